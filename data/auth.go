@@ -0,0 +1,170 @@
+package data
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/privateerproj/privateer-sdk/config"
+	"golang.org/x/oauth2"
+)
+
+// newTokenSource returns the credentials for the GitHub API: the token var when it is set,
+// otherwise an installation token for the GitHub App given by the app-id and app-private-key-path vars
+func newTokenSource(ctx context.Context, cfg *config.Config, transport http.RoundTripper) (oauth2.TokenSource, error) {
+	if token := cfg.GetString("token"); token != "" {
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), nil
+	}
+	appID, _ := cfg.GetVar("app-id")
+	keyPath := cfg.GetString("app-private-key-path")
+	switch {
+	case appID == nil && keyPath == "":
+		return nil, fmt.Errorf("missing required variables: [token] (or [app-id app-private-key-path] for GitHub App authentication)")
+	case appID == nil:
+		return nil, fmt.Errorf("missing required variables: [app-id]")
+	case keyPath == "":
+		return nil, fmt.Errorf("missing required variables: [app-private-key-path]")
+	}
+
+	id, err := strconv.ParseInt(fmt.Sprint(appID), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid app-id %v: %w", appID, err)
+	}
+	key, err := readAppPrivateKey(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.ReuseTokenSource(nil, &appTokenSource{
+		ctx:        ctx,
+		appID:      id,
+		key:        key,
+		owner:      cfg.GetString("owner"),
+		endpoints:  configuredEndpoints(cfg),
+		httpClient: &http.Client{Transport: transport},
+	}), nil
+}
+
+// tokenIdentity names the credentials in use without revealing them, so that cached responses are only
+// reused by the identity that fetched them
+func tokenIdentity(cfg *config.Config) string {
+	if token := cfg.GetString("token"); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		return "token:" + hex.EncodeToString(sum[:])
+	}
+	appID, _ := cfg.GetVar("app-id")
+	return fmt.Sprintf("app:%v:%s", appID, cfg.GetString("owner"))
+}
+
+// newAuthenticatedClient returns an HTTP client that sends the credentials from tokens with each request
+func newAuthenticatedClient(tokens oauth2.TokenSource, transport http.RoundTripper) *http.Client {
+	return &http.Client{Transport: &oauth2.Transport{Source: oauth2.ReuseTokenSource(nil, tokens), Base: transport}}
+}
+
+// appTokenSource exchanges a JWT signed with the GitHub App's private key for a token of the app's
+// installation on the repository owner. Installation tokens expire after an hour, so it is wrapped in
+// oauth2.ReuseTokenSource, which calls Token again for a fresh one as each expires during a long scan.
+type appTokenSource struct {
+	ctx            context.Context
+	appID          int64
+	key            *rsa.PrivateKey
+	owner          string
+	installationID int64
+	endpoints      githubEndpoints
+	httpClient     *http.Client
+}
+
+func (a *appTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := a.signJWT(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	client, err := newRestClient(a.httpClient, a.endpoints)
+	if err != nil {
+		return nil, err
+	}
+	client = client.WithAuthToken(jwt)
+
+	if a.installationID == 0 {
+		a.installationID, err = a.findInstallation(client)
+		if err != nil {
+			return nil, err
+		}
+	}
+	token, _, err := client.Apps.CreateInstallationToken(a.ctx, a.installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installation token for GitHub App %d: %w", a.appID, err)
+	}
+	return &oauth2.Token{AccessToken: token.GetToken(), Expiry: token.GetExpiresAt().Time}, nil
+}
+
+// findInstallation returns the ID of the app's installation on the owner, which may be an organization or a user
+func (a *appTokenSource) findInstallation(client *github.Client) (int64, error) {
+	installation, response, err := client.Apps.FindOrganizationInstallation(a.ctx, a.owner)
+	if err != nil && response != nil && response.StatusCode == http.StatusNotFound {
+		installation, _, err = client.Apps.FindUserInstallation(a.ctx, a.owner)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to find an installation of GitHub App %d for %s: %w", a.appID, a.owner, err)
+	}
+	return installation.GetID(), nil
+}
+
+// signJWT returns the RS256 token that authenticates as the app itself. It is backdated a minute
+// to allow for clock drift and lasts well under the ten minute maximum GitHub accepts.
+func (a *appTokenSource) signJWT(now time.Time) (string, error) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(a.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// readAppPrivateKey reads the PEM encoded private key GitHub generates for an app, in PKCS#1 or PKCS#8 form
+func readAppPrivateKey(keyPath string) (*rsa.PrivateKey, error) {
+	contents, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key at %s is not PEM encoded", keyPath)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,162 @@
+package data
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/privateerproj/privateer-sdk/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestAppKey writes a freshly generated RSA key to a PEM file the way GitHub hands out app private keys
+func newTestAppKey(t *testing.T) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(keyPath, keyPEM, 0o600))
+	return key, keyPath
+}
+
+// verifyJWT checks the signature of an app JWT and returns its claims
+func verifyJWT(t *testing.T, key *rsa.PublicKey, jwt string) map[string]any {
+	parts := strings.Split(jwt, ".")
+	require.Len(t, parts, 3)
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature))
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	claims := map[string]any{}
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	return claims
+}
+
+func TestNewTokenSource(t *testing.T) {
+	_, keyPath := newTestAppKey(t)
+	tests := []struct {
+		name          string
+		vars          map[string]any
+		expectedError string
+	}{
+		{
+			name: "personal token",
+			vars: map[string]any{"token": "token"},
+		},
+		{
+			name: "github app",
+			vars: map[string]any{"app-id": 12345, "app-private-key-path": keyPath},
+		},
+		{
+			name:          "no credentials",
+			vars:          map[string]any{},
+			expectedError: "missing required variables: [token] (or [app-id app-private-key-path] for GitHub App authentication)",
+		},
+		{
+			name:          "app without private key",
+			vars:          map[string]any{"app-id": 12345},
+			expectedError: "missing required variables: [app-private-key-path]",
+		},
+		{
+			name:          "private key without app",
+			vars:          map[string]any{"app-private-key-path": keyPath},
+			expectedError: "missing required variables: [app-id]",
+		},
+		{
+			name:          "non-numeric app id",
+			vars:          map[string]any{"app-id": "my-app", "app-private-key-path": keyPath},
+			expectedError: `invalid app-id my-app: strconv.ParseInt: parsing "my-app": invalid syntax`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := newTokenSource(t.Context(), &config.Config{Vars: tt.vars}, nil)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, tokens)
+		})
+	}
+}
+
+func TestAppTokenSource(t *testing.T) {
+	key, keyPath := newTestAppKey(t)
+	var lookups, exchanges atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/orgs/octocat/installation", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+	})
+	mux.HandleFunc("GET /api/v3/users/octocat/installation", func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		_, _ = w.Write([]byte(`{"id": 42}`))
+	})
+	mux.HandleFunc("POST /api/v3/app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		claims := verifyJWT(t, &key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		assert.Equal(t, "12345", claims["iss"])
+		// expiring within oauth2's refresh margin makes every Token call fetch a new installation token
+		count := exchanges.Add(1)
+		_, _ = fmt.Fprintf(w, `{"token": "installation-token-%d", "expires_at": %q}`, count, time.Now().Add(5*time.Second).Format(time.RFC3339))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	tokens, err := newTokenSource(t.Context(), &config.Config{Vars: map[string]any{
+		"owner":                "octocat",
+		"app-id":               "12345",
+		"app-private-key-path": keyPath,
+		"api-url":              server.URL + "/api/v3",
+	}}, nil)
+	require.NoError(t, err)
+
+	first, err := tokens.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "installation-token-1", first.AccessToken)
+
+	second, err := tokens.Token()
+	require.NoError(t, err)
+	assert.Equal(t, "installation-token-2", second.AccessToken)
+
+	assert.Equal(t, int32(1), lookups.Load(), "the installation is only looked up once")
+}
+
+func TestReadAppPrivateKey(t *testing.T) {
+	key, keyPath := newTestAppKey(t)
+	parsed, err := readAppPrivateKey(keyPath)
+	assert.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pkcs8Path := filepath.Join(t.TempDir(), "pkcs8.pem")
+	require.NoError(t, os.WriteFile(pkcs8Path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), 0o600))
+	parsed, err = readAppPrivateKey(pkcs8Path)
+	assert.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	notPEM := filepath.Join(t.TempDir(), "key.txt")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a key"), 0o600))
+	_, err = readAppPrivateKey(notPEM)
+	assert.EqualError(t, err, fmt.Sprintf("GitHub App private key at %s is not PEM encoded", notPEM))
+}
@@ -0,0 +1,254 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"golang.org/x/sync/errgroup"
+)
+
+type binaryChecker struct {
+	httpClient  *http.Client
+	logger      hclog.Logger
+	owner       string
+	repo        string
+	branch      string
+	rawBase     string
+	local       *localClone
+	ctx         context.Context
+	concurrency int
+}
+
+// binarySniffLimit caps how many files of a remote repository have their content fetched to decide whether they are
+// binary. Files past the cap are reported as unchecked, so that a large repository does not cost thousands of requests.
+var binarySniffLimit = 500
+
+// check decides by extension, name or size where it can, and otherwise sniffs the start of the file's content
+func (bc *binaryChecker) check(entry RepoTreeEntry) (bool, error) {
+	if binary, decided := decideBinaryWithoutContent(entry); decided {
+		return binary, nil
+	}
+	binary, err := bc.checkViaPartialFetch(entry.Path)
+	if err != nil {
+		return false, fmt.Errorf("failed to check binary status via partial fetch for %s: %w", entry.Path, err)
+	}
+	return binary, nil
+}
+
+// decideBinaryWithoutContent reports whether a file is binary when its extension, name or size tells,
+// to avoid fetching its content
+func decideBinaryWithoutContent(entry RepoTreeEntry) (binary, decided bool) {
+	switch {
+	case commonBinaryFileExtension(entry.Path):
+		return true, true
+	case commonAcceptableFileExtension(entry.Path), commonTextFileName(entry.Path), entry.Size == 0:
+		return false, true
+	}
+	return false, false
+}
+
+func (bc *binaryChecker) checkViaPartialFetch(path string) (bool, error) {
+	if bc.local != nil {
+		content, err := bc.local.readPrefix(path, 512)
+		if err != nil {
+			return false, err
+		}
+		return mimeContentTypeIsBinary(content), nil
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	escapedPath := strings.Join(segments, "/")
+	rawBase := bc.rawBase
+	if rawBase == "" {
+		rawBase = RawBase
+	}
+	rawURL := fmt.Sprintf("%s/%s/%s/%s/%s", rawBase, bc.owner, bc.repo, bc.branch, escapedPath)
+
+	parent := bc.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Range", "bytes=0-511")
+
+	resp, err := bc.httpClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, err
+	}
+	
+	return mimeContentTypeIsBinary(content), nil
+}
+
+func mimeContentTypeIsBinary(content []byte) bool {
+    contentType := http.DetectContentType(content)
+
+    switch {
+	case strings.HasPrefix(contentType, "application/"):
+        return true
+    default:
+        return false
+    }
+}
+
+func commonAcceptableFileExtension(path string) bool {
+	// Extract file extension from path
+	lastDot := strings.LastIndex(path, ".")
+	if lastDot == -1 || lastDot == len(path)-1 {
+		return false // No extension or extension is empty
+	}
+	ext := strings.ToLower(path[lastDot:])
+	
+	extensions := []string{
+		".md", ".txt", ".yaml", ".yml", ".json", ".toml", ".ini", ".conf", ".env",
+		".sh", ".bash", ".zsh", ".fish",
+		".c", ".cpp", ".h", ".hpp", ".c++", ".h++", ".cxx", ".hxx", ".cu", ".cuh",
+		".go", ".rs", ".py", ".java", ".js", ".ts", ".jsx", ".tsx",
+		".rb", ".php", ".swift", ".kt", ".scala", ".clj", ".hs",
+		".css", ".scss", ".sass", ".less", ".html", ".htm", ".xml", ".svg",
+		".sql", ".pl", ".lua", ".r", ".m", ".mm", ".dart",
+		".tf", ".tfvars", ".hcl", ".bzl", ".BUILD",
+		".lock", ".log", ".gitignore", ".dockerignore",
+		".mod", ".sum", ".cs", ".fs", ".vb", ".proto", ".rst", ".adoc", ".tex", ".gradle", ".kts", ".groovy", ".sbt",
+		".cmake", ".mk", ".am", ".ac", ".in", ".cfg", ".properties", ".tmpl", ".tpl", ".j2", ".erb",
+		".ex", ".exs", ".erl", ".ml", ".mli", ".jl", ".zig", ".nix", ".vue", ".svelte", ".mjs", ".cjs",
+		".ps1", ".bat", ".cmd", ".patch", ".diff", ".csv", ".tsv", ".graphql", ".gql", ".pem",
+		".csproj", ".sln", ".editorconfig", ".gitattributes", ".gitmodules", ".npmignore",
+	}
+	return slices.Contains(extensions, ext)
+}
+
+// commonTextFileNames are conventional names of text files without a telling extension
+var commonTextFileNames = []string{
+	"makefile", "gnumakefile", "dockerfile", "containerfile", "jenkinsfile", "vagrantfile", "procfile",
+	"gemfile", "rakefile", "brewfile", "podfile", "tiltfile", "build", "workspace",
+	"license", "licence", "copying", "notice", "patents", "readme", "changelog", "authors", "contributors",
+	"maintainers", "owners", "owners_aliases", "codeowners", "security_contacts", "version",
+}
+
+// commonTextFileName reports files whose name alone, such as Makefile or LICENSE, marks them as text
+func commonTextFileName(filePath string) bool {
+	name := strings.ToLower(path.Base(filePath))
+	if slices.Contains(commonTextFileNames, name) {
+		return true
+	}
+	// variants such as Dockerfile.dev
+	base, _, _ := strings.Cut(name, ".")
+	return base == "dockerfile" || base == "containerfile"
+}
+
+// commonBinaryFileExtension reports files whose extension alone marks them as binary
+func commonBinaryFileExtension(path string) bool {
+	lastDot := strings.LastIndex(path, ".")
+	if lastDot == -1 || lastDot == len(path)-1 {
+		return false
+	}
+	ext := strings.ToLower(path[lastDot:])
+
+	extensions := []string{
+		".exe", ".dll", ".so", ".dylib", ".a", ".o", ".obj", ".lib", ".bin", ".wasm",
+		".class", ".jar", ".war", ".ear", ".dex", ".apk",
+		".whl", ".egg", ".pyc", ".pyo",
+		".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".7z", ".rar",
+		".png", ".jpg", ".jpeg", ".gif", ".bmp", ".ico", ".webp", ".pdf",
+		".ttf", ".otf", ".woff", ".woff2",
+	}
+	return slices.Contains(extensions, ext)
+}
+
+// binaryNames returns the file name of each binary path
+func binaryNames(binaryPaths []string) (names []string) {
+	for _, binaryPath := range binaryPaths {
+		names = append(names, path.Base(binaryPath))
+	}
+	return names
+}
+
+type binaryStatus int
+
+const (
+	notBinary binaryStatus = iota
+	isBinary
+	binaryUnchecked
+)
+
+// checkTreeForBinaryPaths returns the full path of every binary file found in the tree, in tree order, and of every
+// file whose content could not be checked. Files are decided without their content where possible, and the rest are
+// sniffed with a limited number of fetches at once. A fetch that fails leaves only that file unchecked.
+func checkTreeForBinaryPaths(entries []RepoTreeEntry, bc *binaryChecker) (binariesFound, unchecked []string) {
+	statuses := make([]binaryStatus, len(entries))
+	var sniff []int
+	for i, entry := range entries {
+		if entry.Type != "blob" {
+			continue
+		}
+		if binary, decided := decideBinaryWithoutContent(entry); decided {
+			if binary {
+				statuses[i] = isBinary
+			}
+			continue
+		}
+		if bc.local == nil && len(sniff) >= binarySniffLimit {
+			statuses[i] = binaryUnchecked
+			continue
+		}
+		sniff = append(sniff, i)
+	}
+
+	limit := bc.concurrency
+	if limit <= 0 {
+		limit = defaultConcurrency
+	}
+	var group errgroup.Group
+	group.SetLimit(limit)
+	for _, i := range sniff {
+		group.Go(func() error {
+			binary, err := bc.checkViaPartialFetch(entries[i].Path)
+			switch {
+			case err != nil:
+				bc.logger.Debug(fmt.Sprintf("failed to check binary status of %s: %s", entries[i].Path, err.Error()))
+				statuses[i] = binaryUnchecked
+			case binary:
+				statuses[i] = isBinary
+			}
+			return nil
+		})
+	}
+	_ = group.Wait()
+
+	for i, status := range statuses {
+		switch status {
+		case isBinary:
+			binariesFound = append(binariesFound, entries[i].Path)
+		case binaryUnchecked:
+			unchecked = append(unchecked, entries[i].Path)
+		}
+	}
+	return binariesFound, unchecked
+}
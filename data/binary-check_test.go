@@ -4,90 +4,138 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-hclog"
 )
 
-func boolPtr(b bool) *bool {
-	return &b
+// newTestBinaryChecker serves the given file contents in place of raw.githubusercontent.com
+func newTestBinaryChecker(t *testing.T, contents map[string][]byte) *binaryChecker {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := contents[strings.TrimPrefix(r.URL.Path, "/test/repo/main/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+
+	httpClient := server.Client()
+	httpClient.Transport = &testTransport{
+		baseURL:   server.URL,
+		transport: http.DefaultTransport,
+	}
+	return &binaryChecker{
+		httpClient: httpClient,
+		logger:     hclog.NewNullLogger(),
+		owner:      "test",
+		repo:       "repo",
+		branch:     "main",
+	}
 }
 
-func TestCheckTreeForBinaries(t *testing.T) {
-	bc := &binaryChecker{logger: hclog.NewNullLogger()}
+var machO = []byte{0xcf, 0xfa, 0xed, 0xfe, 0x00, 0x01, 0x02}
+
+func TestCheckTreeForBinaryNames(t *testing.T) {
+	bc := newTestBinaryChecker(t, map[string][]byte{
+		"LICENSE":                    []byte("Apache License"),
+		"OWNERS":                     []byte("approvers:"),
+		"OWNERS_ALIASES":             []byte("aliases:"),
+		"SECURITY_CONTACTS":          []byte("security@example.com"),
+		"Tiltfile":                   []byte("load('ext://restart_process', 'docker_build_with_restart')"),
+		"dockerignore":               []byte("bin/"),
+		"TECHNICAL_ADVISORY_MEMBERS": []byte("members"),
+		"tool":                       machO,
+		"a/b/c/d/e/f/helper":         machO,
+	})
 
 	tests := []struct {
 		name     string
-		tree     *GraphqlRepoTree
+		entries  []RepoTreeEntry
 		expected []string
 	}{
-		{
-			name:     "nil tree returns nil",
-			tree:     nil,
-			expected: nil,
-		},
 		{
 			name:     "empty tree returns no binaries",
-			tree:     &GraphqlRepoTree{},
+			entries:  nil,
 			expected: nil,
 		},
 		{
 			name: "text files are not flagged as binary",
-			tree: buildTree([]testEntry{
-				{name: "README.md", isBinary: boolPtr(false)},
-				{name: "LICENSE", isBinary: boolPtr(false)},
-				{name: "OWNERS", isBinary: boolPtr(false)},
-				{name: "Tiltfile", isBinary: boolPtr(false)},
-			}),
+			entries: []RepoTreeEntry{
+				{Path: "README.md", Type: "blob", Size: 10},
+				{Path: "LICENSE", Type: "blob", Size: 10},
+				{Path: "OWNERS", Type: "blob", Size: 10},
+				{Path: "Tiltfile", Type: "blob", Size: 10},
+			},
 			expected: nil,
 		},
 		{
 			name: "binary files are correctly detected",
-			tree: buildTree([]testEntry{
-				{name: "app.jar", isBinary: boolPtr(true)},
-				{name: "README.md", isBinary: boolPtr(false)},
-			}),
+			entries: []RepoTreeEntry{
+				{Path: "app.jar", Type: "blob", Size: 10},
+				{Path: "README.md", Type: "blob", Size: 10},
+			},
 			expected: []string{"app.jar"},
 		},
 		{
 			name: "multiple binary files detected",
-			tree: buildTree([]testEntry{
-				{name: "app.exe", isBinary: boolPtr(true)},
-				{name: "lib.dll", isBinary: boolPtr(true)},
-				{name: "main.go", isBinary: boolPtr(false)},
-			}),
+			entries: []RepoTreeEntry{
+				{Path: "app.exe", Type: "blob", Size: 10},
+				{Path: "lib.dll", Type: "blob", Size: 10},
+				{Path: "main.go", Type: "blob", Size: 10},
+			},
 			expected: []string{"app.exe", "lib.dll"},
 		},
 		{
 			name: "nested binary files detected",
-			tree: buildTreeWithNested(
-				[]testEntry{{name: "README.md", isBinary: boolPtr(false)}},
-				[]testEntry{{name: "wrapper.jar", isBinary: boolPtr(true)}},
-			),
+			entries: []RepoTreeEntry{
+				{Path: "README.md", Type: "blob", Size: 10},
+				{Path: "subdir", Type: "tree"},
+				{Path: "subdir/wrapper.jar", Type: "blob", Size: 10},
+			},
 			expected: []string{"wrapper.jar"},
 		},
+		{
+			name: "binaries without an extension detected by content",
+			entries: []RepoTreeEntry{
+				{Path: "tool", Type: "blob", Size: 10},
+				{Path: "a/b/c/d/e/f/helper", Type: "blob", Size: 10},
+			},
+			expected: []string{"tool", "helper"},
+		},
 		{
 			name: "extensionless text files not flagged",
-			tree: buildTree([]testEntry{
-				{name: "OWNERS", isBinary: boolPtr(false)},
-				{name: "OWNERS_ALIASES", isBinary: boolPtr(false)},
-				{name: "SECURITY_CONTACTS", isBinary: boolPtr(false)},
-				{name: "Tiltfile", isBinary: boolPtr(false)},
-				{name: "dockerignore", isBinary: boolPtr(false)},
-				{name: "TECHNICAL_ADVISORY_MEMBERS", isBinary: boolPtr(false)},
-			}),
+			entries: []RepoTreeEntry{
+				{Path: "OWNERS", Type: "blob", Size: 10},
+				{Path: "OWNERS_ALIASES", Type: "blob", Size: 10},
+				{Path: "SECURITY_CONTACTS", Type: "blob", Size: 10},
+				{Path: "Tiltfile", Type: "blob", Size: 10},
+				{Path: "dockerignore", Type: "blob", Size: 10},
+				{Path: "TECHNICAL_ADVISORY_MEMBERS", Type: "blob", Size: 10},
+			},
+			expected: nil,
+		},
+		{
+			name: "empty files and submodules not flagged",
+			entries: []RepoTreeEntry{
+				{Path: "missing-but-empty", Type: "blob", Size: 0},
+				{Path: "third_party/lib", Type: "commit"},
+			},
 			expected: nil,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := checkTreeForBinaries(tt.tree, bc)
-			// TODO: Add expected error test cases
-			if err != nil {
-				t.Errorf("checkTreeForBinaries() error = %v", err)
+			binaryPaths, unchecked := checkTreeForBinaryPaths(tt.entries, bc)
+			if len(unchecked) > 0 {
+				t.Errorf("checkTreeForBinaryPaths() left files unchecked: %v", unchecked)
 				return
 			}
+			result := binaryNames(binaryPaths)
 
 			if len(result) != len(tt.expected) {
 				t.Errorf("got %d binaries, want %d\ngot: %v\nwant: %v",
@@ -104,50 +152,131 @@ func TestCheckTreeForBinaries(t *testing.T) {
 	}
 }
 
+func TestCheckTreeForBinaryPathsFetchError(t *testing.T) {
+	bc := newTestBinaryChecker(t, map[string][]byte{"helper": machO})
+	entries := []RepoTreeEntry{
+		{Path: "unknown", Type: "blob", Size: 10},
+		{Path: "helper", Type: "blob", Size: 10},
+	}
+	binaries, unchecked := checkTreeForBinaryPaths(entries, bc)
+	if len(binaries) != 1 || binaries[0] != "helper" {
+		t.Errorf("got binaries %v, want [helper]", binaries)
+	}
+	if len(unchecked) != 1 || unchecked[0] != "unknown" {
+		t.Errorf("got unchecked %v, want [unknown]", unchecked)
+	}
+}
+
+func TestCheckTreeForBinaryPathsSniffLimit(t *testing.T) {
+	defer func(limit int) { binarySniffLimit = limit }(binarySniffLimit)
+	binarySniffLimit = 2
+
+	bc := newTestBinaryChecker(t, map[string][]byte{
+		"a": machO,
+		"b": []byte("plain text"),
+		"c": machO,
+		"d": machO,
+	})
+	entries := []RepoTreeEntry{
+		{Path: "a", Type: "blob", Size: 10},
+		{Path: "main.go", Type: "blob", Size: 10},
+		{Path: "b", Type: "blob", Size: 10},
+		{Path: "c", Type: "blob", Size: 10},
+		{Path: "app.exe", Type: "blob", Size: 10},
+		{Path: "d", Type: "blob", Size: 10},
+	}
+	binaries, unchecked := checkTreeForBinaryPaths(entries, bc)
+	expectedBinaries := []string{"a", "app.exe"}
+	expectedUnchecked := []string{"c", "d"}
+	if strings.Join(binaries, ",") != strings.Join(expectedBinaries, ",") {
+		t.Errorf("got binaries %v, want %v", binaries, expectedBinaries)
+	}
+	if strings.Join(unchecked, ",") != strings.Join(expectedUnchecked, ",") {
+		t.Errorf("got unchecked %v, want %v", unchecked, expectedUnchecked)
+	}
+}
+
+func TestCommonTextFileName(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"Makefile", true},
+		{"docs/LICENSE", true},
+		{"Dockerfile", true},
+		{"build/Dockerfile.dev", true},
+		{"Containerfile.release", true},
+		{"CODEOWNERS", true},
+		{"helper", false},
+		{"bin/run", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if result := commonTextFileName(tt.path); result != tt.expected {
+				t.Errorf("commonTextFileName(%q) = %v, want %v", tt.path, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckTreeForBinaryPaths(t *testing.T) {
+	bc := newTestBinaryChecker(t, nil)
+	entries := []RepoTreeEntry{
+		{Path: "app.exe", Type: "blob", Size: 10},
+		{Path: "subdir", Type: "tree"},
+		{Path: "subdir/wrapper.jar", Type: "blob", Size: 10},
+		{Path: "subdir/main.go", Type: "blob", Size: 10},
+	}
+
+	result, unchecked := checkTreeForBinaryPaths(entries, bc)
+	if len(unchecked) > 0 {
+		t.Fatalf("checkTreeForBinaryPaths() left files unchecked: %v", unchecked)
+	}
+	expected := []string{"app.exe", "subdir/wrapper.jar"}
+	if len(result) != len(expected) {
+		t.Fatalf("got %v, want %v", result, expected)
+	}
+	for i, binaryPath := range expected {
+		if result[i] != binaryPath {
+			t.Errorf("binary[%d] = %q, want %q", i, result[i], binaryPath)
+		}
+	}
+}
+
 func TestBinaryCheckerIsBinary(t *testing.T) {
 	bc := &binaryChecker{logger: hclog.NewNullLogger()}
 
-	t.Run("isBinary true returns true", func(t *testing.T) {
-		result, err := bc.check(boolPtr(true), false, "any-file")
+	t.Run("binary extension returns true", func(t *testing.T) {
+		result, err := bc.check(RepoTreeEntry{Path: "bin/app.exe", Type: "blob", Size: 10})
 		if err != nil {
 			t.Errorf("check() error = %v", err)
 			return
 		}
 		if !result {
-			t.Error("expected isBinary=true to return true")
+			t.Error("expected .exe file to be binary")
 		}
 	})
 
-	t.Run("isBinary false returns false", func(t *testing.T) {
-		result, err := bc.check(boolPtr(false), false, "any-file")
+	t.Run("text extension returns false", func(t *testing.T) {
+		result, err := bc.check(RepoTreeEntry{Path: "main.go", Type: "blob", Size: 10})
 		if err != nil {
 			t.Errorf("check() error = %v", err)
 			return
 		}
 		if result {
-			t.Error("expected isBinary=false to return false")
+			t.Error("expected .go file not to be binary")
 		}
 	})
 
-	t.Run("isBinary false takes precedence over truncated", func(t *testing.T) {
-		result, err := bc.check(boolPtr(false), true, "any-file")
+	t.Run("empty file returns false", func(t *testing.T) {
+		result, err := bc.check(RepoTreeEntry{Path: "any-file", Type: "blob", Size: 0})
 		if err != nil {
 			t.Errorf("check() error = %v", err)
 			return
 		}
 		if result {
-			t.Error("expected isBinary=false to return false even when truncated")
-		}
-	})
-
-	t.Run("nil isBinary and not truncated returns false", func(t *testing.T) {
-		result, err := bc.check(nil, false, "any-file")
-		if err != nil {
-			t.Errorf("check() error = %v", err)
-			return
-		}
-		if result {
-			t.Error("expected nil isBinary with isTruncated=false to return false")
+			t.Error("expected empty file not to be binary")
 		}
 	})
 }
@@ -393,229 +522,3 @@ func (t *testTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.URL.Host = serverURL.Host
 	return t.transport.RoundTrip(req)
 }
-
-type testEntry struct {
-	name     string
-	isBinary *bool
-}
-
-func buildTree(entries []testEntry) *GraphqlRepoTree {
-	tree := &GraphqlRepoTree{}
-
-	for _, e := range entries {
-		entry := struct {
-			Name   string
-			Type   string
-			Path   string
-			Object *struct {
-				Blob struct {
-					IsBinary    *bool
-					IsTruncated bool
-				} `graphql:"... on Blob"`
-				Tree struct {
-					Entries []struct {
-						Name   string
-						Type   string
-						Path   string
-						Object *struct {
-							Blob struct {
-								IsBinary    *bool
-								IsTruncated bool
-							} `graphql:"... on Blob"`
-							Tree struct {
-								Entries []struct {
-									Name   string
-									Type   string
-									Path   string
-									Object *struct {
-										Blob struct {
-											IsBinary    *bool
-											IsTruncated bool
-										} `graphql:"... on Blob"`
-									} `graphql:"object"`
-								}
-							} `graphql:"... on Tree"`
-						} `graphql:"object"`
-					}
-				} `graphql:"... on Tree"`
-			} `graphql:"object"`
-		}{
-			Name: e.name,
-			Type: "blob",
-			Path: e.name,
-		}
-		entry.Object = &struct {
-			Blob struct {
-				IsBinary    *bool
-				IsTruncated bool
-			} `graphql:"... on Blob"`
-			Tree struct {
-				Entries []struct {
-					Name   string
-					Type   string
-					Path   string
-					Object *struct {
-						Blob struct {
-							IsBinary    *bool
-							IsTruncated bool
-						} `graphql:"... on Blob"`
-						Tree struct {
-							Entries []struct {
-								Name   string
-								Type   string
-								Path   string
-								Object *struct {
-									Blob struct {
-										IsBinary    *bool
-										IsTruncated bool
-									} `graphql:"... on Blob"`
-								} `graphql:"object"`
-							}
-						} `graphql:"... on Tree"`
-					} `graphql:"object"`
-				}
-			} `graphql:"... on Tree"`
-		}{}
-		entry.Object.Blob.IsBinary = e.isBinary
-
-		tree.Repository.Object.Tree.Entries = append(tree.Repository.Object.Tree.Entries, entry)
-	}
-
-	return tree
-}
-
-func buildTreeWithNested(rootEntries []testEntry, subEntries []testEntry) *GraphqlRepoTree {
-	tree := buildTree(rootEntries)
-
-	dirEntry := struct {
-		Name   string
-		Type   string
-		Path   string
-		Object *struct {
-			Blob struct {
-				IsBinary    *bool
-				IsTruncated bool
-			} `graphql:"... on Blob"`
-			Tree struct {
-				Entries []struct {
-					Name   string
-					Type   string
-					Path   string
-					Object *struct {
-						Blob struct {
-							IsBinary    *bool
-							IsTruncated bool
-						} `graphql:"... on Blob"`
-						Tree struct {
-							Entries []struct {
-								Name   string
-								Type   string
-								Path   string
-								Object *struct {
-									Blob struct {
-										IsBinary    *bool
-										IsTruncated bool
-									} `graphql:"... on Blob"`
-								} `graphql:"object"`
-							}
-						} `graphql:"... on Tree"`
-					} `graphql:"object"`
-				}
-			} `graphql:"... on Tree"`
-		} `graphql:"object"`
-	}{
-		Name: "subdir",
-		Type: "tree",
-		Path: "subdir",
-	}
-
-	dirEntry.Object = &struct {
-		Blob struct {
-			IsBinary    *bool
-			IsTruncated bool
-		} `graphql:"... on Blob"`
-		Tree struct {
-			Entries []struct {
-				Name   string
-				Type   string
-				Path   string
-				Object *struct {
-					Blob struct {
-						IsBinary    *bool
-						IsTruncated bool
-					} `graphql:"... on Blob"`
-					Tree struct {
-						Entries []struct {
-							Name   string
-							Type   string
-							Path   string
-							Object *struct {
-								Blob struct {
-									IsBinary    *bool
-									IsTruncated bool
-								} `graphql:"... on Blob"`
-							} `graphql:"object"`
-						}
-					} `graphql:"... on Tree"`
-				} `graphql:"object"`
-			}
-		} `graphql:"... on Tree"`
-	}{}
-
-	for _, e := range subEntries {
-		subEntry := struct {
-			Name   string
-			Type   string
-			Path   string
-			Object *struct {
-				Blob struct {
-					IsBinary    *bool
-					IsTruncated bool
-				} `graphql:"... on Blob"`
-				Tree struct {
-					Entries []struct {
-						Name   string
-						Type   string
-						Path   string
-						Object *struct {
-							Blob struct {
-								IsBinary    *bool
-								IsTruncated bool
-							} `graphql:"... on Blob"`
-						} `graphql:"object"`
-					}
-				} `graphql:"... on Tree"`
-			} `graphql:"object"`
-		}{
-			Name: e.name,
-			Type: "blob",
-			Path: "subdir/" + e.name,
-		}
-		subEntry.Object = &struct {
-			Blob struct {
-				IsBinary    *bool
-				IsTruncated bool
-			} `graphql:"... on Blob"`
-			Tree struct {
-				Entries []struct {
-					Name   string
-					Type   string
-					Path   string
-					Object *struct {
-						Blob struct {
-							IsBinary    *bool
-							IsTruncated bool
-						} `graphql:"... on Blob"`
-					} `graphql:"object"`
-				}
-			} `graphql:"... on Tree"`
-		}{}
-		subEntry.Object.Blob.IsBinary = e.isBinary
-
-		dirEntry.Object.Tree.Entries = append(dirEntry.Object.Tree.Entries, subEntry)
-	}
-
-	tree.Repository.Object.Tree.Entries = append(tree.Repository.Object.Tree.Entries, dirEntry)
-
-	return tree
-}
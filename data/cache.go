@@ -0,0 +1,228 @@
+package data
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/privateerproj/privateer-sdk/config"
+)
+
+// defaultGraphqlCacheTTL is how long GraphQL results are reused when cache-ttl is not set
+const defaultGraphqlCacheTTL = time.Hour
+
+// cacheTransport keeps responses in the cache-dir so that repeated scans of an unchanged repository
+// make few billable API calls. GET responses with an ETag or Last-Modified header are revalidated with a
+// conditional request, since GitHub does not count a 304 against the rate limit. GraphQL queries cannot be
+// revalidated, so their results are reused until cache-ttl has passed. Entries are keyed by the credentials
+// in use as well as the request, so that one identity is never served another's private data.
+type cacheTransport struct {
+	base       http.RoundTripper
+	dir        string
+	ttl        time.Duration
+	identity   string
+	graphqlURL string
+	logger     hclog.Logger
+	now        func() time.Time
+}
+
+// cacheEntry is the JSON file stored for each cached response
+type cacheEntry struct {
+	URL      string      `json:"url"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// newCacheTransport wraps base with a cacheTransport when the cache-dir var is set, and otherwise returns base
+func newCacheTransport(cfg *config.Config, base http.RoundTripper) (http.RoundTripper, error) {
+	dir := cfg.GetString("cache-dir")
+	if dir == "" {
+		return base, nil
+	}
+	ttl := defaultGraphqlCacheTTL
+	if cacheTTL := cfg.GetString("cache-ttl"); cacheTTL != "" {
+		parsed, err := time.ParseDuration(cacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache-ttl %s: %w", cacheTTL, err)
+		}
+		ttl = parsed
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &cacheTransport{
+		base:       base,
+		dir:        dir,
+		ttl:        ttl,
+		identity:   tokenIdentity(cfg),
+		graphqlURL: configuredEndpoints(cfg).Graphql,
+		logger:     logger,
+		now:        time.Now,
+	}, nil
+}
+
+func (c *cacheTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	switch {
+	case request.Method == http.MethodGet && request.Header.Get("If-None-Match") == "" && request.Header.Get("Range") == "":
+		return c.revalidate(request)
+	case request.Method == http.MethodPost && request.URL.String() == c.graphqlURL && request.GetBody != nil:
+		return c.expiring(request)
+	}
+	return c.base.RoundTrip(request)
+}
+
+// revalidate sends a conditional request for a GET response already in the cache, and serves the cached copy on a 304
+func (c *cacheTransport) revalidate(request *http.Request) (*http.Response, error) {
+	key := c.key(request.URL.String(), nil)
+	entry, cached := c.read(key)
+	if cached {
+		request = request.Clone(request.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			request.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+			request.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	response, err := c.base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	if cached && response.StatusCode == http.StatusNotModified {
+		_ = response.Body.Close()
+		c.logger.Trace(fmt.Sprintf("Using cached response for %s", request.URL.Redacted()))
+		return entry.response(request), nil
+	}
+	if response.StatusCode == http.StatusOK && (response.Header.Get("ETag") != "" || response.Header.Get("Last-Modified") != "") {
+		c.store(key, request, response)
+	}
+	return response, nil
+}
+
+// expiring serves a GraphQL result from the cache until it is older than the ttl
+func (c *cacheTransport) expiring(request *http.Request) (*http.Response, error) {
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	query, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	key := c.key(request.URL.String(), query)
+	if entry, cached := c.read(key); cached && c.now().Sub(entry.StoredAt) < c.ttl {
+		c.logger.Trace(fmt.Sprintf("Using cached GraphQL result from %s", entry.StoredAt.Format(time.RFC3339)))
+		return entry.response(request), nil
+	}
+
+	response, err := c.base.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusOK {
+		c.store(key, request, response)
+	}
+	return response, nil
+}
+
+func (c *cacheTransport) key(url string, body []byte) string {
+	hash := sha256.New()
+	for _, part := range [][]byte{[]byte(c.identity), []byte(url), body} {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (c *cacheTransport) read(key string) (entry cacheEntry, ok bool) {
+	contents, err := os.ReadFile(filepath.Join(c.dir, key+".json"))
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(contents, &entry); err != nil {
+		c.logger.Trace(fmt.Sprintf("Ignoring unreadable cache entry %s: %s", key, err.Error()))
+		return entry, false
+	}
+	return entry, true
+}
+
+// store saves the response to the cache and restores its body for the caller. GraphQL results that carry
+// errors are not stored, since the query may succeed when retried. Failing to write to the cache is only logged.
+func (c *cacheTransport) store(key string, request *http.Request, response *http.Response) {
+	body, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || (request.Method == http.MethodPost && hasGraphqlErrors(body)) {
+		return
+	}
+
+	contents, err := json.Marshal(cacheEntry{
+		URL:      request.URL.Redacted(),
+		Header:   response.Header,
+		Body:     body,
+		StoredAt: c.now(),
+	})
+	if err == nil {
+		err = writeFileAtomic(filepath.Join(c.dir, key+".json"), contents)
+	}
+	if err != nil {
+		c.logger.Debug(fmt.Sprintf("Failed to cache response for %s: %s", request.URL.Redacted(), err.Error()))
+	}
+}
+
+func (e cacheEntry) response(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       request,
+	}
+}
+
+func hasGraphqlErrors(body []byte) bool {
+	var result struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	return json.Unmarshal(body, &result) != nil || len(result.Errors) > 0
+}
+
+// writeFileAtomic writes through a temporary file so that concurrent scans never read a partial entry
+func writeFileAtomic(filePath string, contents []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(filePath), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(contents)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), filePath)
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+	}
+	return err
+}
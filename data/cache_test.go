@@ -0,0 +1,149 @@
+package data
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/privateerproj/privateer-sdk/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCacheTransport returns a cacheTransport over server whose clock is controlled by the test
+func newTestCacheTransport(t *testing.T, server *httptest.Server, dir, token string, now *time.Time) *cacheTransport {
+	transport, err := newCacheTransport(&config.Config{
+		Vars: map[string]any{
+			"token":       token,
+			"cache-dir":   dir,
+			"cache-ttl":   "10m",
+			"graphql-url": server.URL + "/graphql",
+		},
+		Logger: hclog.NewNullLogger(),
+	}, http.DefaultTransport)
+	require.NoError(t, err)
+	cache := transport.(*cacheTransport)
+	cache.now = func() time.Time { return *now }
+	return cache
+}
+
+func fetch(t *testing.T, transport http.RoundTripper, method, url, body string) (int, string) {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	request, err := http.NewRequest(method, url, reader)
+	require.NoError(t, err)
+	response, err := (&http.Client{Transport: transport}).Do(request)
+	require.NoError(t, err)
+	defer func() { _ = response.Body.Close() }()
+	responseBody, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+	return response.StatusCode, string(responseBody)
+}
+
+func TestCacheTransportRevalidatesWithETag(t *testing.T) {
+	var requests, notModified atomic.Int32
+	content := "v1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		etag := `"` + content + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	now := time.Now()
+	cache := newTestCacheTransport(t, server, dir, "token-a", &now)
+
+	status, body := fetch(t, cache, http.MethodGet, server.URL+"/repos/o/r/releases", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "v1", body)
+
+	status, body = fetch(t, cache, http.MethodGet, server.URL+"/repos/o/r/releases", "")
+	assert.Equal(t, http.StatusOK, status, "a 304 is served to the caller as the cached 200")
+	assert.Equal(t, "v1", body)
+	assert.Equal(t, int32(1), notModified.Load())
+
+	// a later scan with a new transport over the same directory revalidates too
+	status, body = fetch(t, newTestCacheTransport(t, server, dir, "token-a", &now), http.MethodGet, server.URL+"/repos/o/r/releases", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "v1", body)
+	assert.Equal(t, int32(2), notModified.Load())
+
+	// another identity never sees the cached response
+	_, _ = fetch(t, newTestCacheTransport(t, server, dir, "token-b", &now), http.MethodGet, server.URL+"/repos/o/r/releases", "")
+	assert.Equal(t, int32(2), notModified.Load())
+
+	content = "v2"
+	_, body = fetch(t, cache, http.MethodGet, server.URL+"/repos/o/r/releases", "")
+	assert.Equal(t, "v2", body, "a changed resource replaces the cached copy")
+	assert.Equal(t, int32(5), requests.Load())
+}
+
+func TestCacheTransportExpiresGraphqlResults(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		query, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(query), "broken") {
+			_, _ = w.Write([]byte(`{"errors": [{"message": "Field 'broken' doesn't exist"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": {"repository": {"name": "repo"}}}`))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	cache := newTestCacheTransport(t, server, t.TempDir(), "token", &now)
+	query := `{"query": "{repository{name}}"}`
+
+	_, body := fetch(t, cache, http.MethodPost, server.URL+"/graphql", query)
+	assert.Equal(t, `{"data": {"repository": {"name": "repo"}}}`, body)
+	_, body = fetch(t, cache, http.MethodPost, server.URL+"/graphql", query)
+	assert.Equal(t, `{"data": {"repository": {"name": "repo"}}}`, body)
+	assert.Equal(t, int32(1), requests.Load(), "the result is reused within the ttl")
+
+	_, _ = fetch(t, cache, http.MethodPost, server.URL+"/graphql", `{"query": "{viewer{login}}"}`)
+	assert.Equal(t, int32(2), requests.Load(), "a different query is fetched")
+
+	now = now.Add(11 * time.Minute)
+	_, _ = fetch(t, cache, http.MethodPost, server.URL+"/graphql", query)
+	assert.Equal(t, int32(3), requests.Load(), "the result is refetched after the ttl")
+
+	_, _ = fetch(t, cache, http.MethodPost, server.URL+"/graphql", `{"query": "{broken}"}`)
+	_, _ = fetch(t, cache, http.MethodPost, server.URL+"/graphql", `{"query": "{broken}"}`)
+	assert.Equal(t, int32(5), requests.Load(), "results with errors are not cached")
+
+	_, _ = fetch(t, cache, http.MethodPost, server.URL+"/app/installations/1/access_tokens", query)
+	_, _ = fetch(t, cache, http.MethodPost, server.URL+"/app/installations/1/access_tokens", query)
+	assert.Equal(t, int32(7), requests.Load(), "only GraphQL queries are cached among POST requests")
+}
+
+func TestNewCacheTransport(t *testing.T) {
+	base := http.DefaultTransport
+	transport, err := newCacheTransport(&config.Config{Vars: map[string]any{}}, base)
+	assert.NoError(t, err)
+	assert.Equal(t, base, transport, "caching is off without a cache-dir")
+
+	_, err = newCacheTransport(&config.Config{Vars: map[string]any{"cache-dir": t.TempDir(), "cache-ttl": "soon"}}, base)
+	assert.EqualError(t, err, `invalid cache-ttl soon: time: invalid duration "soon"`)
+
+	dir := t.TempDir() + "/nested/cache"
+	_, err = newCacheTransport(&config.Config{Vars: map[string]any{"cache-dir": dir}}, base)
+	assert.NoError(t, err)
+	_, err = os.Stat(dir)
+	assert.NoError(t, err, "the cache directory is created")
+}
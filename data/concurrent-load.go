@@ -0,0 +1,39 @@
+package data
+
+import (
+	"github.com/privateerproj/privateer-sdk/config"
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultConcurrency is how many API requests the loader makes at once when the concurrency var is not set
+const defaultConcurrency = 4
+
+// loaderConcurrency returns the concurrency var, or the default when it is not a positive number
+func loaderConcurrency(cfg *config.Config) int {
+	if cfg == nil || cfg.GetInt("concurrency") <= 0 {
+		return defaultConcurrency
+	}
+	return cfg.GetInt("concurrency")
+}
+
+// loadConcurrently runs independent loading tasks with at most limit running at once. Every task runs to completion,
+// and the error returned is that of the earliest failed task in the list, so the reported error does not depend on
+// which request happened to finish first.
+func loadConcurrently(limit int, tasks ...func() error) error {
+	var group errgroup.Group
+	group.SetLimit(limit)
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		group.Go(func() error {
+			errs[i] = task()
+			return errs[i]
+		})
+	}
+	_ = group.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package data
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/privateerproj/privateer-sdk/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConcurrently(t *testing.T) {
+	t.Run("runs every task within the limit", func(t *testing.T) {
+		var running, peak, completed atomic.Int32
+		task := func() error {
+			current := running.Add(1)
+			for {
+				highest := peak.Load()
+				if current <= highest || peak.CompareAndSwap(highest, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			completed.Add(1)
+			return nil
+		}
+
+		err := loadConcurrently(2, task, task, task, task, task)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(5), completed.Load())
+		assert.Equal(t, int32(2), peak.Load())
+	})
+
+	t.Run("reports the earliest failed task in the list", func(t *testing.T) {
+		first := errors.New("graphql query failed")
+		second := errors.New("rest request failed")
+		var completed atomic.Bool
+		err := loadConcurrently(3,
+			func() error {
+				// fails last, after the later task has already failed
+				time.Sleep(20 * time.Millisecond)
+				return first
+			},
+			func() error { return second },
+			func() error {
+				completed.Store(true)
+				return nil
+			},
+		)
+		assert.Equal(t, first, err)
+		assert.True(t, completed.Load(), "a failure does not stop the other tasks")
+	})
+}
+
+func TestLoaderConcurrency(t *testing.T) {
+	assert.Equal(t, defaultConcurrency, loaderConcurrency(&config.Config{Vars: map[string]any{}}))
+	assert.Equal(t, defaultConcurrency, loaderConcurrency(&config.Config{Vars: map[string]any{"concurrency": 0}}))
+	assert.Equal(t, 8, loaderConcurrency(&config.Config{Vars: map[string]any{"concurrency": 8}}))
+}
+
+func TestGetSubdirContentByPathConcurrent(t *testing.T) {
+	var requests atomic.Int32
+	httpClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposContentsByOwnerByRepoByPath,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				_, _ = w.Write(mock.MustMarshal([]github.RepositoryContent{
+					{Name: github.Ptr("ci.yml"), Type: github.Ptr("file"), Path: github.Ptr(".github/workflows/ci.yml")},
+				}))
+			}),
+		),
+	)
+	restData := &RestData{
+		owner:    "test-owner",
+		repo:     "test-repo",
+		ghClient: github.NewClient(httpClient),
+		lazy:     &restMemos{},
+		contents: RepoContent{
+			Content: []*github.RepositoryContent{
+				{Name: github.Ptr(".github"), Type: github.Ptr("dir"), Path: github.Ptr(".github")},
+			},
+			SubContent: map[string]RepoContent{
+				".github": {
+					Content: []*github.RepositoryContent{
+						{Name: github.Ptr("workflows"), Type: github.Ptr("dir"), Path: github.Ptr(".github/workflows")},
+					},
+					SubContent: map[string]RepoContent{},
+				},
+			},
+		},
+	}
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			result, err := restData.contents.GetSubdirContentByPath(restData, ".github/workflows")
+			assert.NoError(t, err)
+			assert.Len(t, result.Content, 1)
+		})
+	}
+	wg.Wait()
+
+	requests.Store(0)
+	_, err := restData.contents.GetSubdirContentByPath(restData, ".github/workflows")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), requests.Load(), "the directory is cached once fetched")
+}
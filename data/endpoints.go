@@ -0,0 +1,83 @@
+package data
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/privateerproj/privateer-sdk/config"
+	"github.com/shurcooL/githubv4"
+)
+
+// The github.com endpoints, which the api-url, graphql-url and raw-url config vars replace to scan GitHub Enterprise Server
+var (
+	APIBase    = "https://api.github.com"
+	GraphqlURL = "https://api.github.com/graphql"
+	RawBase    = "https://raw.githubusercontent.com"
+	WebBase    = "https://github.com"
+)
+
+// githubEndpoints are the base URLs of the GitHub instance being scanned
+type githubEndpoints struct {
+	API     string
+	Graphql string
+	Raw     string
+	// Web is where repositories and their files are linked, which has no config var of its own
+	Web string
+}
+
+// configuredEndpoints returns the endpoints set in the config, falling back to github.com for any that are not set.
+// A GitHub Enterprise Server instance at ghes.example.com would typically use https://ghes.example.com/api/v3,
+// https://ghes.example.com/api/graphql and https://ghes.example.com/raw, and is linked at https://ghes.example.com.
+func configuredEndpoints(cfg *config.Config) githubEndpoints {
+	endpoints := githubEndpoints{API: APIBase, Graphql: GraphqlURL, Raw: RawBase, Web: WebBase}
+	if cfg == nil {
+		return endpoints
+	}
+	if apiURL := cfg.GetString("api-url"); apiURL != "" {
+		endpoints.API = strings.TrimSuffix(apiURL, "/")
+		endpoints.Web = webBaseForAPI(endpoints.API)
+	}
+	if graphqlURL := cfg.GetString("graphql-url"); graphqlURL != "" {
+		endpoints.Graphql = graphqlURL
+	}
+	if rawURL := cfg.GetString("raw-url"); rawURL != "" {
+		endpoints.Raw = strings.TrimSuffix(rawURL, "/")
+	}
+	return endpoints
+}
+
+// webBaseForAPI returns the web base URL of the GitHub instance that serves the REST API at apiURL.
+// Enterprise Server serves both from the same host.
+func webBaseForAPI(apiURL string) string {
+	parsed, err := url.Parse(apiURL)
+	if err != nil || parsed.Host == "" || apiURL == APIBase {
+		return WebBase
+	}
+	return fmt.Sprintf("%s://%s", parsed.Scheme, parsed.Host)
+}
+
+// newRestClient returns a GitHub REST client that sends its requests to the API endpoint
+func newRestClient(httpClient *http.Client, endpoints githubEndpoints) (*github.Client, error) {
+	client := github.NewClient(httpClient)
+	if endpoints.API == APIBase {
+		return client, nil
+	}
+	baseURL, err := url.Parse(endpoints.API + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid api-url %s: %w", endpoints.API, err)
+	}
+	client.BaseURL = baseURL
+	client.UploadURL = baseURL
+	return client, nil
+}
+
+// newGraphqlClient returns a GitHub GraphQL client that sends its queries to the GraphQL endpoint
+func newGraphqlClient(httpClient *http.Client, endpoints githubEndpoints) *githubv4.Client {
+	if endpoints.Graphql == GraphqlURL {
+		return githubv4.NewClient(httpClient)
+	}
+	return githubv4.NewEnterpriseClient(endpoints.Graphql, httpClient)
+}
@@ -0,0 +1,233 @@
+package data
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/privateerproj/privateer-sdk/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGHES stands in for a GitHub Enterprise Server instance, serving the REST API under /api/v3,
+// GraphQL at /api/graphql and raw file contents under /raw, and records every request it receives
+type fakeGHES struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+}
+
+func newFakeGHES(t *testing.T) *fakeGHES {
+	ghes := &fakeGHES{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"repository":{"name":"repo","defaultBranchRef":{"name":"main"}}}}`))
+	})
+	mux.HandleFunc("GET /api/v3/repos/owner/repo/releases", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]ReleaseData{{Name: "v1.0.0", TagName: "v1.0.0"}})
+	})
+	mux.HandleFunc("GET /api/v3/repos/owner/repo", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name": "repo", "default_branch": "main"}`))
+	})
+	mux.HandleFunc("GET /api/v3/repos/owner/repo/languages", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"Go": 100}`))
+	})
+	mux.HandleFunc("GET /raw/owner/repo/main/tool", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(machO)
+	})
+	ghes.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ghes.mu.Lock()
+		ghes.requests = append(ghes.requests, r.Method+" "+r.URL.Path)
+		ghes.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ghes.Close)
+	return ghes
+}
+
+func (g *fakeGHES) config() *config.Config {
+	return &config.Config{
+		Vars: map[string]any{
+			"owner":       "owner",
+			"repo":        "repo",
+			"token":       "token",
+			"api-url":     g.URL + "/api/v3/",
+			"graphql-url": g.URL + "/api/graphql",
+			"raw-url":     g.URL + "/raw",
+		},
+		Logger: hclog.NewNullLogger(),
+	}
+}
+
+func (g *fakeGHES) received(request string) bool {
+	return g.count(request) > 0
+}
+
+// receivedPath reports whether any request path contained part
+func (g *fakeGHES) receivedPath(part string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, r := range g.requests {
+		if strings.Contains(r, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// count returns how many times the server received request
+func (g *fakeGHES) count(request string) (count int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, r := range g.requests {
+		if r == request {
+			count++
+		}
+	}
+	return count
+}
+
+func TestConfiguredEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     map[string]any
+		expected githubEndpoints
+	}{
+		{
+			name:     "defaults to github.com",
+			vars:     map[string]any{},
+			expected: githubEndpoints{API: APIBase, Graphql: GraphqlURL, Raw: RawBase, Web: WebBase},
+		},
+		{
+			name: "enterprise server endpoints with trailing slashes",
+			vars: map[string]any{
+				"api-url":     "https://ghes.example.com/api/v3/",
+				"graphql-url": "https://ghes.example.com/api/graphql",
+				"raw-url":     "https://ghes.example.com/raw/",
+			},
+			expected: githubEndpoints{
+				API:     "https://ghes.example.com/api/v3",
+				Graphql: "https://ghes.example.com/api/graphql",
+				Raw:     "https://ghes.example.com/raw",
+				Web:     "https://ghes.example.com",
+			},
+		},
+		{
+			name:     "only some endpoints set",
+			vars:     map[string]any{"api-url": "https://ghes.example.com/api/v3"},
+			expected: githubEndpoints{API: "https://ghes.example.com/api/v3", Graphql: GraphqlURL, Raw: RawBase, Web: "https://ghes.example.com"},
+		},
+		{
+			name:     "github.com api set explicitly",
+			vars:     map[string]any{"api-url": "https://api.github.com/"},
+			expected: githubEndpoints{API: APIBase, Graphql: GraphqlURL, Raw: RawBase, Web: WebBase},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, configuredEndpoints(&config.Config{Vars: tt.vars}))
+		})
+	}
+}
+
+func TestParseRepositoryURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		vars      map[string]any
+		repoURL   string
+		wantOwner string
+		wantRepo  string
+		wantOK    bool
+	}{
+		{
+			name:      "github.com repository",
+			vars:      map[string]any{},
+			repoURL:   "https://github.com/org/tool.git",
+			wantOwner: "org",
+			wantRepo:  "tool",
+			wantOK:    true,
+		},
+		{
+			name:    "repository on another host",
+			vars:    map[string]any{},
+			repoURL: "https://gitlab.com/org/tool",
+		},
+		{
+			name:    "repository without a name",
+			vars:    map[string]any{},
+			repoURL: "https://github.com/org",
+		},
+		{
+			name:      "enterprise server repository",
+			vars:      map[string]any{"api-url": "https://ghes.example.com/api/v3"},
+			repoURL:   "https://GHES.example.com/org/tool/",
+			wantOwner: "org",
+			wantRepo:  "tool",
+			wantOK:    true,
+		},
+		{
+			name:    "github.com repository when scanning enterprise server",
+			vars:    map[string]any{"api-url": "https://ghes.example.com/api/v3"},
+			repoURL: "https://github.com/org/tool",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := Payload{Config: &config.Config{Vars: tt.vars}}
+			owner, repo, ok := payload.ParseRepositoryURL(tt.repoURL)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantOwner, owner)
+			assert.Equal(t, tt.wantRepo, repo)
+		})
+	}
+}
+
+func TestEnterpriseServerEndpoints(t *testing.T) {
+	ghes := newFakeGHES(t)
+	cfg := ghes.config()
+	transport := newRetryTransport(cfg.Logger)
+	tokens, err := newTokenSource(t.Context(), cfg, transport)
+	require.NoError(t, err)
+	httpClient := newAuthenticatedClient(tokens, transport)
+
+	graphql, err := getGraphqlRepoData(t.Context(), cfg, newGraphqlClient(httpClient, configuredEndpoints(cfg)))
+	require.NoError(t, err)
+	assert.Equal(t, "main", graphql.Repository.DefaultBranchRef.Name)
+
+	ghClient, err := newRestClient(httpClient, configuredEndpoints(cfg))
+	require.NoError(t, err)
+	languages, _, err := ghClient.Repositories.ListLanguages(t.Context(), "owner", "repo")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"Go": 100}, languages)
+
+	rest, err := getRestData(t.Context(), ghClient, tokens, &http.Client{Transport: transport}, cfg)
+	require.NoError(t, err)
+	assert.True(t, ghes.received("GET /api/v3/repos/owner/repo/contents/"))
+	releases, err := rest.Releases()
+	assert.NoError(t, err)
+	assert.Equal(t, []ReleaseData{{Name: "v1.0.0", TagName: "v1.0.0"}}, releases)
+	_, _ = rest.WorkflowPermissions()
+	assert.True(t, ghes.received("GET /api/v3/repos/owner/repo/actions"))
+	_, _ = rest.SecurityAdvisories()
+	assert.True(t, ghes.received("GET /api/v3/repos/owner/repo/security-advisories"))
+
+	bc := &binaryChecker{
+		httpClient: httpClient,
+		logger:     hclog.NewNullLogger(),
+		owner:      "owner",
+		repo:       "repo",
+		branch:     "main",
+		rawBase:    configuredEndpoints(cfg).Raw,
+	}
+	isBinary, err := bc.checkViaPartialFetch("tool")
+	assert.NoError(t, err)
+	assert.True(t, isBinary)
+}
@@ -8,16 +8,6 @@ type GraphqlRepoData struct {
 		HasIssuesEnabled        bool
 		IsSecurityPolicyEnabled bool
 
-		Object struct {
-			Tree struct {
-				Entries []struct {
-					Name string
-					Type string // "blob" for files, "tree" for directories
-					Path string
-				}
-			} `graphql:"... on Tree"`
-		} `graphql:"object(expression: \"HEAD:\")"`
-
 		DefaultBranchRef struct {
 			Name          string
 			RefUpdateRule struct {
@@ -11,31 +11,58 @@ type DependencyManifestsPage struct {
 	Repository struct {
 		DependencyGraphManifests struct {
 			TotalCount int
-		}
+			Nodes      []ManifestNode
+			PageInfo   struct {
+				EndCursor   githubv4.String
+				HasNextPage bool
+			}
+		} `graphql:"dependencyGraphManifests(first: 100, after: $cursor)"`
 	} `graphql:"repository(owner: $owner, name: $name)"`
 }
 
+// ManifestNode is a dependency manifest or lockfile found by the GitHub dependency graph.
+// Only the first dependency is requested, which is enough to identify the manifest's package manager.
 type ManifestNode struct {
 	Filename     string
-	Dependencies []Dependency
+	Dependencies struct {
+		Nodes []Dependency
+	} `graphql:"dependencies(first: 1)"`
 }
 
 type Dependency struct {
-	PackageName  string
-	Requirements string
+	PackageName    string
+	Requirements   string
+	PackageManager string
 }
 
-func countDependencyManifests(client *githubv4.Client, cfg *config.Config) (int, error) {
-	var query DependencyManifestsPage
-	variables := map[string]any{
-		"owner": githubv4.String(cfg.GetString("owner")),
-		"name":  githubv4.String(cfg.GetString("repo")),
+// PackageManager returns the package manager reported for the manifest's dependencies, or "" when it has none
+func (m ManifestNode) PackageManager() string {
+	if len(m.Dependencies.Nodes) == 0 {
+		return ""
 	}
+	return m.Dependencies.Nodes[0].PackageManager
+}
 
-	err := client.Query(context.Background(), &query, variables)
-	if err != nil {
-		return 0, err
+func getDependencyManifests(ctx context.Context, client *githubv4.Client, cfg *config.Config) (count int, manifests []ManifestNode, err error) {
+	variables := map[string]any{
+		"owner":  githubv4.String(cfg.GetString("owner")),
+		"name":   githubv4.String(cfg.GetString("repo")),
+		"cursor": (*githubv4.String)(nil),
 	}
 
-	return query.Repository.DependencyGraphManifests.TotalCount, nil
+	for {
+		var query DependencyManifestsPage
+		err = client.Query(ctx, &query, variables)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		page := query.Repository.DependencyGraphManifests
+		count = page.TotalCount
+		manifests = append(manifests, page.Nodes...)
+		if !page.PageInfo.HasNextPage {
+			return count, manifests, nil
+		}
+		variables["cursor"] = githubv4.NewString(page.PageInfo.EndCursor)
+	}
 }
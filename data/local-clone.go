@@ -0,0 +1,190 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/google/go-github/v74/github"
+)
+
+// ErrAPIUnavailable is returned for GitHub API calls when the payload is loaded from a local clone
+var ErrAPIUnavailable = errors.New("the GitHub API is not used when scanning a local clone")
+
+// LicenseFiles are the names GitHub recognizes for a license file in the repository root
+var LicenseFiles = []string{"license", "license.md", "license.txt", "licence", "licence.md", "copying", "copying.md", "copying.txt"}
+
+// codeFileExtensions mark a file as source code when deciding whether a local clone is a code repository
+var codeFileExtensions = []string{
+	".go", ".rs", ".py", ".java", ".kt", ".scala", ".js", ".mjs", ".ts", ".jsx", ".tsx",
+	".rb", ".php", ".swift", ".c", ".cc", ".cpp", ".cxx", ".h", ".hpp", ".cs", ".m", ".mm",
+	".dart", ".lua", ".pl", ".sh", ".hs", ".clj", ".ex", ".exs", ".erl", ".zig",
+}
+
+// localClone reads the files and history of a git checkout on disk in place of the GitHub API.
+// The tree lists the files tracked in the index, so staged changes are included and build output is not,
+// while file contents are read from the working tree.
+type localClone struct {
+	path       string
+	repository *git.Repository
+}
+
+func openLocalClone(clonePath string) (*localClone, error) {
+	repository, err := git.PlainOpen(clonePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open git repository at %s: %w", clonePath, err)
+	}
+	return &localClone{path: clonePath, repository: repository}, nil
+}
+
+// head returns the checked out branch name and commit
+func (l *localClone) head() (branch string, commit string, err error) {
+	ref, err := l.repository.Head()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if ref.Name().IsBranch() {
+		branch = ref.Name().Short()
+	}
+	return branch, ref.Hash().String(), nil
+}
+
+// tree lists the tracked files with the directories that contain them, each directory before its contents
+func (l *localClone) tree() (entries []RepoTreeEntry, err error) {
+	idx, err := l.repository.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git index: %w", err)
+	}
+	seenDirs := make(map[string]bool)
+	for _, entry := range idx.Entries {
+		var dirs []string
+		for dir := path.Dir(entry.Name); dir != "." && !seenDirs[dir]; dir = path.Dir(dir) {
+			seenDirs[dir] = true
+			dirs = append(dirs, dir)
+		}
+		slices.Reverse(dirs)
+		for _, dir := range dirs {
+			entries = append(entries, RepoTreeEntry{Path: dir, Type: "tree"})
+		}
+
+		entryType := "blob"
+		if entry.Mode == filemode.Submodule {
+			entryType = "commit"
+		}
+		entries = append(entries, RepoTreeEntry{Path: entry.Name, Type: entryType, Size: int(entry.Size)})
+	}
+	return entries, nil
+}
+
+// readFile returns the working tree copy of a file in the shape the GitHub contents API uses
+func (l *localClone) readFile(filePath string) (*github.RepositoryContent, error) {
+	content, err := os.ReadFile(l.filePath(filePath))
+	if err != nil {
+		return nil, err
+	}
+	return &github.RepositoryContent{
+		Type:     github.Ptr("file"),
+		Name:     github.Ptr(path.Base(filePath)),
+		Path:     github.Ptr(filePath),
+		Size:     github.Ptr(len(content)),
+		Encoding: github.Ptr("base64"),
+		Content:  github.Ptr(base64.StdEncoding.EncodeToString(content)),
+	}, nil
+}
+
+// readPrefix returns up to n bytes from the start of a file
+func (l *localClone) readPrefix(filePath string, n int) ([]byte, error) {
+	file, err := os.Open(l.filePath(filePath))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return io.ReadAll(io.LimitReader(file, int64(n)))
+}
+
+func (l *localClone) filePath(filePath string) string {
+	return filepath.Join(l.path, filepath.FromSlash(filePath))
+}
+
+// releases lists the tags as releases, newest first, since releases themselves only exist on GitHub
+func (l *localClone) releases() (releases []ReleaseData, err error) {
+	tags, err := l.repository.Tags()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	tagTimes := make(map[string]time.Time)
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if tag, err := l.repository.TagObject(ref.Hash()); err == nil {
+			tagTimes[name] = tag.Tagger.When
+		} else if commit, err := l.repository.CommitObject(ref.Hash()); err == nil {
+			tagTimes[name] = commit.Committer.When
+		}
+		releases = append(releases, ReleaseData{Name: name, TagName: name})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	slices.SortStableFunc(releases, func(a, b ReleaseData) int {
+		return tagTimes[b.TagName].Compare(tagTimes[a.TagName])
+	})
+	return releases, nil
+}
+
+// listDirectory converts the tree entries directly inside dir to the shape the GitHub contents API uses
+func listDirectory(entries []RepoTreeEntry, dir string) (contents []*github.RepositoryContent) {
+	if dir == "" {
+		dir = "."
+	}
+	for _, entry := range entries {
+		if path.Dir(entry.Path) != dir {
+			continue
+		}
+		contentType := "file"
+		switch entry.Type {
+		case "tree":
+			contentType = "dir"
+		case "commit":
+			contentType = "submodule"
+		}
+		contents = append(contents, &github.RepositoryContent{
+			Type: github.Ptr(contentType),
+			Name: github.Ptr(path.Base(entry.Path)),
+			Path: github.Ptr(entry.Path),
+			Size: github.Ptr(entry.Size),
+		})
+	}
+	return contents
+}
+
+// isCodeTree reports whether any tracked file is written in a programming language
+func isCodeTree(entries []RepoTreeEntry) bool {
+	return slices.ContainsFunc(entries, func(entry RepoTreeEntry) bool {
+		return entry.Type == "blob" && slices.Contains(codeFileExtensions, strings.ToLower(path.Ext(entry.Path)))
+	})
+}
+
+// spdxIdentifier returns the identifier declared by an SPDX-License-Identifier line, or "" when there is none
+func spdxIdentifier(content []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		_, identifier, found := strings.Cut(scanner.Text(), "SPDX-License-Identifier:")
+		if found {
+			return strings.TrimSpace(identifier)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,167 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/go-hclog"
+	"github.com/privateerproj/privateer-sdk/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClone creates a git repository with a tagged commit, a second commit with an annotated tag,
+// and an untracked file that must not appear in the tree
+func newTestClone(t *testing.T) string {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := repository.Worktree()
+	require.NoError(t, err)
+
+	writeFile := func(name, content string) {
+		fullPath := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0o755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0o644))
+	}
+	signature := func(when time.Time) *object.Signature {
+		return &object.Signature{Name: "test", Email: "test@example.com", When: when}
+	}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	writeFile("LICENSE", "SPDX-License-Identifier: Apache-2.0\n\nApache License")
+	writeFile("README.md", "# Test\n")
+	writeFile("cmd/tool/main.go", "package main\n")
+	writeFile(".github/workflows/ci.yml", "on: push\n")
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	first, err := worktree.Commit("first", &git.CommitOptions{Author: signature(start)})
+	require.NoError(t, err)
+	_, err = repository.CreateTag("v1.0.0", first, nil)
+	require.NoError(t, err)
+
+	writeFile("internal/a/b/c/d/e/helper", "\x00\x01\x02")
+	_, err = worktree.Add(".")
+	require.NoError(t, err)
+	second, err := worktree.Commit("second", &git.CommitOptions{Author: signature(start.Add(time.Hour))})
+	require.NoError(t, err)
+	_, err = repository.CreateTag("v1.1.0", second, &git.CreateTagOptions{Tagger: signature(start.Add(time.Hour)), Message: "v1.1.0"})
+	require.NoError(t, err)
+
+	writeFile("build/output.exe", "MZ")
+	return dir
+}
+
+func TestLocalCloneTree(t *testing.T) {
+	clone, err := openLocalClone(newTestClone(t))
+	require.NoError(t, err)
+
+	entries, err := clone.tree()
+	assert.NoError(t, err)
+	assert.Equal(t, []RepoTreeEntry{
+		{Path: ".github", Type: "tree"},
+		{Path: ".github/workflows", Type: "tree"},
+		{Path: ".github/workflows/ci.yml", Type: "blob", Size: 9},
+		{Path: "LICENSE", Type: "blob", Size: 51},
+		{Path: "README.md", Type: "blob", Size: 7},
+		{Path: "cmd", Type: "tree"},
+		{Path: "cmd/tool", Type: "tree"},
+		{Path: "cmd/tool/main.go", Type: "blob", Size: 13},
+		{Path: "internal", Type: "tree"},
+		{Path: "internal/a", Type: "tree"},
+		{Path: "internal/a/b", Type: "tree"},
+		{Path: "internal/a/b/c", Type: "tree"},
+		{Path: "internal/a/b/c/d", Type: "tree"},
+		{Path: "internal/a/b/c/d/e", Type: "tree"},
+		{Path: "internal/a/b/c/d/e/helper", Type: "blob", Size: 3},
+	}, entries)
+
+	assert.Equal(t, []string{".github", "LICENSE", "README.md", "cmd", "internal"}, contentNames(listDirectory(entries, "")))
+	assert.Equal(t, []string{"ci.yml"}, contentNames(listDirectory(entries, ".github/workflows")))
+	assert.True(t, isCodeTree(entries))
+}
+
+func TestLocalCloneReleases(t *testing.T) {
+	clone, err := openLocalClone(newTestClone(t))
+	require.NoError(t, err)
+
+	releases, err := clone.releases()
+	assert.NoError(t, err)
+	assert.Equal(t, []ReleaseData{
+		{Name: "v1.1.0", TagName: "v1.1.0"},
+		{Name: "v1.0.0", TagName: "v1.0.0"},
+	}, releases)
+}
+
+func TestLoadLocalClone(t *testing.T) {
+	cfg := &config.Config{
+		Vars: map[string]any{
+			"owner":      "test-owner",
+			"repo":       "test-repo",
+			"local-path": newTestClone(t),
+		},
+		Logger: hclog.NewNullLogger(),
+	}
+
+	loaded, err := Loader(cfg)
+	require.NoError(t, err)
+	payload := loaded.(Payload)
+
+	assert.True(t, payload.IsLocalClone)
+	isCodeRepo, err := payload.IsCodeRepo()
+	assert.NoError(t, err)
+	assert.True(t, isCodeRepo)
+	assert.Equal(t, "master", payload.Repository.DefaultBranchRef.Name)
+	assert.Equal(t, "LICENSE", payload.Repository.LicenseInfo.Url)
+	assert.Equal(t, "Apache-2.0", payload.Repository.LicenseInfo.SpdxId)
+	releases, err := payload.Releases()
+	assert.NoError(t, err)
+	assert.Len(t, releases, 2)
+	assert.Equal(t, "README.md", payload.checkFile("readme.md"))
+
+	workflows, err := payload.Workflows()
+	assert.NoError(t, err)
+	if assert.Len(t, workflows, 1) {
+		content, err := workflows[0].GetContent()
+		assert.NoError(t, err)
+		assert.Equal(t, "on: push\n", content)
+	}
+
+	binaries, unchecked, err := payload.SuspectedBinaryPaths()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"internal/a/b/c/d/e/helper"}, binaries)
+	assert.Empty(t, unchecked)
+
+	_, _, err = payload.DependencyManifests()
+	assert.ErrorIs(t, err, ErrAPIUnavailable)
+
+	_, err = payload.MakeApiCall(APIBase+"/repos/test-owner/test-repo/releases", true)
+	assert.ErrorIs(t, err, ErrAPIUnavailable)
+}
+
+func TestLoaderRequiresToken(t *testing.T) {
+	cfg := &config.Config{
+		Vars:   map[string]any{"owner": "test-owner", "repo": "test-repo"},
+		Logger: hclog.NewNullLogger(),
+	}
+
+	_, err := Loader(cfg)
+	assert.EqualError(t, err, "missing required variables: [token] (or [app-id app-private-key-path] for GitHub App authentication)")
+}
+
+func TestSpdxIdentifier(t *testing.T) {
+	assert.Equal(t, "MIT", spdxIdentifier([]byte("// SPDX-License-Identifier: MIT\npackage main")))
+	assert.Equal(t, "", spdxIdentifier([]byte("MIT License\n\nCopyright")))
+}
+
+func contentNames(contents []*github.RepositoryContent) (names []string) {
+	for _, content := range contents {
+		names = append(names, content.GetName())
+	}
+	return names
+}
@@ -0,0 +1,78 @@
+package data
+
+import (
+	"sync"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// memo holds data that is fetched the first time a step asks for it. The fetch runs once even when several
+// steps ask at the same time, and its error is kept with the result so that a failing endpoint is not called
+// again by every step that needs it.
+type memo[T any] struct {
+	once  sync.Once
+	value T
+	err   error
+}
+
+func (m *memo[T]) get(fetch func() (T, error)) (T, error) {
+	m.once.Do(func() {
+		m.value, m.err = fetch()
+	})
+	return m.value, m.err
+}
+
+// set stores a result in place of the fetch, for payloads that are not built by the Loader
+func (m *memo[T]) set(value T, err error) {
+	m.once.Do(func() {
+		m.value, m.err = value, err
+	})
+}
+
+// restMemos are the lazily fetched parts of RestData. They are held by pointer,
+// so every copy of a payload shares what any one of them has fetched.
+type restMemos struct {
+	releases            memo[[]ReleaseData]
+	securityAdvisories  memo[[]SecurityAdvisory]
+	workflowPermissions memo[WorkflowPermissions]
+	workflows           memo[[]*github.RepositoryContent]
+	tree                memo[[]RepoTreeEntry]
+	isCodeRepo          memo[bool]
+	secretsPolicyDocs   memo[[]MarkdownDocument]
+
+	rulesetsMu sync.Mutex
+	rulesets   map[string]*memo[[]Ruleset]
+
+	// contentsMu guards the SubContent caches of RestData.contents, which steps and the loader fill in concurrently
+	contentsMu sync.RWMutex
+}
+
+// branchRulesets returns the memo for the rulesets of one branch
+func (m *restMemos) branchRulesets(branch string) *memo[[]Ruleset] {
+	m.rulesetsMu.Lock()
+	defer m.rulesetsMu.Unlock()
+	if m.rulesets == nil {
+		m.rulesets = make(map[string]*memo[[]Ruleset])
+	}
+	if m.rulesets[branch] == nil {
+		m.rulesets[branch] = &memo[[]Ruleset]{}
+	}
+	return m.rulesets[branch]
+}
+
+// payloadMemos are the lazily fetched parts of Payload that need more than the REST API
+type payloadMemos struct {
+	dependencyManifests  memo[dependencyManifests]
+	suspectedBinaryPaths memo[binaryScan]
+	securityPosture      memo[SecurityPosture]
+}
+
+type binaryScan struct {
+	binaries  []string
+	unchecked []string
+}
+
+type dependencyManifests struct {
+	count     int
+	manifests []ManifestNode
+}
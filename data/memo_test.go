@@ -0,0 +1,86 @@
+package data
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemo(t *testing.T) {
+	t.Run("concurrent callers share one fetch", func(t *testing.T) {
+		var m memo[[]string]
+		var fetches atomic.Int32
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := m.get(func() ([]string, error) {
+					fetches.Add(1)
+					return []string{"v1.0.0"}, nil
+				})
+				assert.NoError(t, err)
+				assert.Equal(t, []string{"v1.0.0"}, value)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), fetches.Load())
+	})
+
+	t.Run("error is cached", func(t *testing.T) {
+		var m memo[int]
+		var fetches int
+		fetch := func() (int, error) {
+			fetches++
+			return 0, errors.New("unexpected response: 502 Bad Gateway")
+		}
+		_, err := m.get(fetch)
+		assert.EqualError(t, err, "unexpected response: 502 Bad Gateway")
+		_, err = m.get(fetch)
+		assert.EqualError(t, err, "unexpected response: 502 Bad Gateway")
+		assert.Equal(t, 1, fetches)
+	})
+
+	t.Run("set replaces the fetch", func(t *testing.T) {
+		var m memo[int]
+		m.set(3, nil)
+		value, err := m.get(func() (int, error) {
+			t.Fatal("fetch should not be called once a value is set")
+			return 0, nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, value)
+	})
+}
+
+func TestLoaderFetchesLazily(t *testing.T) {
+	ghes := newFakeGHES(t)
+	loaded, err := Loader(ghes.config())
+	require.NoError(t, err)
+	payload := loaded.(Payload)
+
+	// controls that only need the license and releases, such as OSPS-LE-*, never list the tree or workflows
+	assert.False(t, ghes.receivedPath("/git/trees/"))
+	assert.False(t, ghes.receivedPath("/workflows"))
+	assert.False(t, ghes.receivedPath("/releases"))
+
+	releases, err := payload.Releases()
+	assert.NoError(t, err)
+	assert.Len(t, releases, 1)
+	_, err = payload.Releases()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ghes.count("GET /api/v3/repos/owner/repo/releases"))
+
+	// a copy of the payload, as each step receives, shares what has already been fetched
+	copied := payload
+	_, err = copied.Tree()
+	assert.Error(t, err)
+	_, err = payload.Tree()
+	assert.Error(t, err)
+	assert.Equal(t, 1, ghes.count("GET /api/v3/repos/owner/repo/git/trees/HEAD"))
+	assert.False(t, ghes.receivedPath("/workflows"))
+}
@@ -2,8 +2,12 @@ package data
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 
 	"github.com/google/go-github/v74/github"
 	"github.com/privateerproj/privateer-sdk/config"
@@ -14,108 +18,345 @@ import (
 type Payload struct {
 	*GraphqlRepoData
 	*RestData
-	Config                   *config.Config
-	SuspectedBinaries        []string
-	RepositoryMetadata       RepositoryMetadata
-	DependencyManifestsCount int
-	IsCodeRepo               bool
-	SecurityPosture          SecurityPosture
-	client                   *githubv4.Client
-	httpClient               *http.Client
+	Config             *config.Config
+	RepositoryMetadata RepositoryMetadata
+	IsLocalClone       bool // read from a local checkout, so API-only fields are empty
+	repository         *github.Repository
+	fetched            *payloadMemos
+	client             *githubv4.Client
+	httpClient         *http.Client
+	transport          http.RoundTripper
+	stop               func()
 }
 
 func Loader(config *config.Config) (payload any, err error) {
-	graphql, client, httpClient, err := getGraphqlRepoData(config)
+	if config.GetString("local-path") != "" {
+		return loadLocalClone(config)
+	}
+	ctx, stop, err := newScanContext(config)
 	if err != nil {
 		return nil, err
 	}
-
-	ghClient := github.NewClient(oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: config.GetString("token")},
-	)))
-
-	repo, repositoryMetadata, err := loadRepositoryMetadata(ghClient, config.GetString("owner"), config.GetString("repo"))
+	defer func() {
+		if err != nil {
+			stop()
+		}
+	}()
+	transport := newRetryTransport(config.Logger)
+	tokens, err := newTokenSource(ctx, config, transport)
 	if err != nil {
 		return nil, err
 	}
-
-	dependencyManifestsCount, err := countDependencyManifests(client, config)
+	cached, err := newCacheTransport(config, transport)
+	if err != nil {
+		return nil, err
+	}
+	httpClient := newAuthenticatedClient(tokens, cached)
+	client := newGraphqlClient(httpClient, configuredEndpoints(config))
+	ghClient, err := newRestClient(httpClient, configuredEndpoints(config))
 	if err != nil {
 		return nil, err
 	}
 
-	rest, err := getRestData(ghClient, config)
+	// The queries do not depend on each other, so they run at the same time. Data that only some steps use,
+	// such as releases, workflows, the tree, the languages and the security posture, is fetched when first asked for.
+	var (
+		graphql            *GraphqlRepoData
+		repo               *github.Repository
+		repositoryMetadata RepositoryMetadata
+		rest               *RestData
+	)
+	err = loadConcurrently(loaderConcurrency(config),
+		func() (err error) {
+			graphql, err = getGraphqlRepoData(ctx, config, client)
+			return err
+		},
+		func() (err error) {
+			repo, repositoryMetadata, err = loadRepositoryMetadata(ctx, ghClient, config.GetString("owner"), config.GetString("repo"))
+			return err
+		},
+		func() (err error) {
+			rest, err = getRestData(ctx, ghClient, tokens, &http.Client{Transport: cached}, config)
+			return err
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	isCodeRepo, err := rest.IsCodeRepo()
+	return any(Payload{
+		GraphqlRepoData:    graphql,
+		RestData:           rest,
+		Config:             config,
+		RepositoryMetadata: repositoryMetadata,
+		repository:         repo,
+		fetched:            &payloadMemos{},
+		client:             client,
+		httpClient:         httpClient,
+		transport:          transport,
+		stop:               stop,
+	}), nil
+}
+
+// loadLocalClone builds a payload from the git checkout at the local-path config var without calling the GitHub API.
+// Files, Security Insights, workflows, the license and tags are read from disk, while fields only the API provides,
+// such as branch protection, organization settings and the dependency graph, are left empty.
+func loadLocalClone(config *config.Config) (payload any, err error) {
+	ctx, stop, err := newScanContext(config)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			stop()
+		}
+	}()
+	local, err := openLocalClone(config.GetString("local-path"))
+	if err != nil {
+		return nil, err
+	}
+	branch, commit, err := local.head()
 	if err != nil {
 		return nil, err
 	}
 
-	securityPosture, err := buildSecurityPosture(repo, *rest)
+	rest := &RestData{
+		Config: config,
+		local:  local,
+		ctx:    ctx,
+	}
+	err = rest.Setup()
 	if err != nil {
 		return nil, err
 	}
 
+	graphql := &GraphqlRepoData{}
+	graphql.Repository.Name = rest.repo
+	graphql.Repository.DefaultBranchRef.Name = branch
+	graphql.Repository.DefaultBranchRef.Target.OID = commit
+	for _, file := range rest.contents.Content {
+		if file.GetType() != "file" || !slices.Contains(LicenseFiles, strings.ToLower(file.GetName())) {
+			continue
+		}
+		graphql.Repository.LicenseInfo.Name = file.GetName()
+		graphql.Repository.LicenseInfo.Url = file.GetPath()
+		if license, err := local.readPrefix(file.GetPath(), 4096); err == nil {
+			graphql.Repository.LicenseInfo.SpdxId = spdxIdentifier(license)
+		}
+		break
+	}
+
 	return any(Payload{
-		GraphqlRepoData:          graphql,
-		RestData:                 rest,
-		Config:                   config,
-		RepositoryMetadata:       repositoryMetadata,
-		DependencyManifestsCount: dependencyManifestsCount,
-		IsCodeRepo:               isCodeRepo,
-		client:                   client,
-		httpClient:               httpClient,
-		SecurityPosture:          securityPosture,
+		GraphqlRepoData:    graphql,
+		RestData:           rest,
+		Config:             config,
+		RepositoryMetadata: &GitHubRepositoryMetadata{ghRepo: &github.Repository{}},
+		repository:         &github.Repository{},
+		IsLocalClone:       true,
+		fetched:            &payloadMemos{},
+		stop:               stop,
 	}), nil
 }
 
-func getGraphqlRepoData(config *config.Config) (data *GraphqlRepoData, client *githubv4.Client, httpClient *http.Client, err error) {
-	src := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: config.GetString("token")},
-	)
-	httpClient = oauth2.NewClient(context.Background(), src)
-	client = githubv4.NewClient(httpClient)
+// Close releases the scan context for callers that keep running after evaluating the payload, stopping its signal
+// handler and timeout. A plugin does not need to, since the process exits once evaluation is complete.
+// Any step that still runs afterwards is not evaluated.
+func (p *Payload) Close() {
+	if p.stop != nil {
+		p.stop()
+	}
+}
+
+// SecurityPosture returns how the repository guards against leaked secrets and the policy it documents for them.
+// It is built when first asked for, from the repository settings and the documents that may hold a secrets policy.
+func (p *Payload) SecurityPosture() (SecurityPosture, error) {
+	return p.memos().securityPosture.get(func() (SecurityPosture, error) {
+		if p.RestData == nil {
+			return nil, errors.New("repository data is not available in the payload")
+		}
+		return buildSecurityPosture(p.repository, *p.RestData)
+	})
+}
 
+// Context returns the scan context, which is cancelled when the scan times out or is interrupted
+func (p *Payload) Context() context.Context {
+	if p.RestData == nil {
+		return context.Background()
+	}
+	return p.context()
+}
+
+// LoadSubprojectPayload loads a lightweight payload for another repository of the project.
+// It holds the GraphQL repository data and repository metadata, but skips the repository contents and
+// Security Insights that Loader fetches. Everything else is fetched on first use, as for Loader.
+func (p *Payload) LoadSubprojectPayload(owner, repo string) (Payload, error) {
+	cfg := subprojectConfig(p.Config, owner, repo)
+	ctx := p.Context()
+	tokens, err := newTokenSource(ctx, cfg, p.transport)
+	if err != nil {
+		return Payload{}, err
+	}
+	cached, err := newCacheTransport(cfg, p.transport)
+	if err != nil {
+		return Payload{}, err
+	}
+	httpClient := newAuthenticatedClient(tokens, cached)
+	client := newGraphqlClient(httpClient, configuredEndpoints(cfg))
+
+	graphql, err := getGraphqlRepoData(ctx, cfg, client)
+	if err != nil {
+		return Payload{}, err
+	}
+
+	ghClient, err := newRestClient(httpClient, configuredEndpoints(cfg))
+	if err != nil {
+		return Payload{}, err
+	}
+	repository, repositoryMetadata, err := loadRepositoryMetadata(ctx, ghClient, owner, repo)
+	if err != nil {
+		return Payload{}, err
+	}
+
+	rest := &RestData{
+		owner:      owner,
+		repo:       repo,
+		tokens:     tokens,
+		ctx:        ctx,
+		Config:     cfg,
+		ghClient:   ghClient,
+		HttpClient: &http.Client{Transport: cached},
+		apiBase:    configuredEndpoints(cfg).API,
+		lazy:       &restMemos{},
+	}
+	rest.ensureInsightsInitialized()
+
+	return Payload{
+		GraphqlRepoData:    graphql,
+		RestData:           rest,
+		Config:             cfg,
+		RepositoryMetadata: repositoryMetadata,
+		repository:         repository,
+		fetched:            &payloadMemos{},
+		client:             client,
+		httpClient:         httpClient,
+		transport:          p.transport,
+	}, nil
+}
+
+// subprojectConfig returns a copy of cfg that targets owner/repo
+func subprojectConfig(cfg *config.Config, owner, repo string) *config.Config {
+	subConfig := *cfg
+	subConfig.Vars = make(map[string]any, len(cfg.Vars))
+	for key, value := range cfg.Vars {
+		subConfig.Vars[key] = value
+	}
+	subConfig.Vars["owner"] = owner
+	subConfig.Vars["repo"] = repo
+	return &subConfig
+}
+
+// ParseRepositoryURL returns the owner and name of a repository hosted on the same GitHub instance as this payload
+func (p *Payload) ParseRepositoryURL(repoURL string) (owner, repo string, ok bool) {
+	web, err := url.Parse(configuredEndpoints(p.Config).Web)
+	if err != nil {
+		return "", "", false
+	}
+	parsed, err := url.Parse(strings.TrimSpace(repoURL))
+	if err != nil || !strings.EqualFold(parsed.Host, web.Host) {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), true
+}
+
+func getGraphqlRepoData(ctx context.Context, config *config.Config, client *githubv4.Client) (data *GraphqlRepoData, err error) {
 	variables := map[string]any{
 		"owner": githubv4.String(config.GetString("owner")),
 		"name":  githubv4.String(config.GetString("repo")),
 	}
 
-	err = client.Query(context.Background(), &data, variables)
+	err = client.Query(ctx, &data, variables)
 	if err != nil {
 		config.Logger.Error(fmt.Sprintf("Error querying GitHub GraphQL API: %s", err.Error()))
 	}
-	return data, client, httpClient, err
+	return data, err
 }
 
-func getRestData(ghClient *github.Client, config *config.Config) (data *RestData, err error) {
+func getRestData(ctx context.Context, ghClient *github.Client, tokens oauth2.TokenSource, httpClient HttpClient, config *config.Config) (data *RestData, err error) {
 	r := &RestData{
-		ghClient: ghClient,
-		Config:   config,
+		ctx:        ctx,
+		ghClient:   ghClient,
+		tokens:     tokens,
+		HttpClient: httpClient,
+		Config:     config,
 	}
 	err = r.Setup()
 	return r, err
 }
 
-func (p *Payload) GetSuspectedBinaries() (suspectedBinaries []string, err error) {
-	branch := p.Repository.DefaultBranchRef.Name
-	tree, err := fetchGraphqlRepoTree(p.Config, p.client, branch)
-	if err != nil {
-		return nil, err
+// memos returns the lazily fetched data, which a Payload built outside the loaders starts without
+func (p *Payload) memos() *payloadMemos {
+	if p.fetched == nil {
+		p.fetched = &payloadMemos{}
 	}
-	bc := &binaryChecker{
-		httpClient: p.httpClient,
-		logger:     p.Config.Logger,
-		owner:      p.Config.GetString("owner"),
-		repo:       p.Config.GetString("repo"),
-		branch:     branch,
+	return p.fetched
+}
+
+// DependencyManifests returns the manifests in the repository's dependency graph and how many there are
+func (p *Payload) DependencyManifests() (count int, manifests []ManifestNode, err error) {
+	result, err := p.memos().dependencyManifests.get(func() (result dependencyManifests, err error) {
+		if p.client == nil {
+			return result, ErrAPIUnavailable
+		}
+		result.count, result.manifests, err = getDependencyManifests(p.Context(), p.client, p.Config)
+		return result, err
+	})
+	return result.count, result.manifests, err
+}
+
+// SuspectedBinaries returns the file name of every suspected binary in the repository tree,
+// and the full path of every file that could not be checked
+func (p *Payload) SuspectedBinaries() (suspectedBinaries, unchecked []string, err error) {
+	paths, unchecked, err := p.SuspectedBinaryPaths()
+	if err != nil {
+		return nil, nil, err
 	}
-	binaryFileNames, err := checkTreeForBinaries(tree, bc)
+	return binaryNames(paths), unchecked, nil
+}
+
+// SuspectedBinaryPaths returns the full path of every suspected binary in the repository tree,
+// and of every file that could not be checked
+func (p *Payload) SuspectedBinaryPaths() (suspectedBinaries, unchecked []string, err error) {
+	scan, err := p.memos().suspectedBinaryPaths.get(func() (scan binaryScan, err error) {
+		entries, bc, err := p.fetchTreeForBinaryCheck()
+		if err != nil {
+			return scan, err
+		}
+		scan.binaries, scan.unchecked = checkTreeForBinaryPaths(entries, bc)
+		return scan, nil
+	})
+	return scan.binaries, scan.unchecked, err
+}
+
+func (p *Payload) fetchTreeForBinaryCheck() (entries []RepoTreeEntry, bc *binaryChecker, err error) {
+	branch := p.Repository.DefaultBranchRef.Name
+	entries, err = p.Tree()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	bc = &binaryChecker{
+		httpClient:  p.httpClient,
+		logger:      p.Config.Logger,
+		owner:       p.Config.GetString("owner"),
+		repo:        p.Config.GetString("repo"),
+		branch:      branch,
+		rawBase:     configuredEndpoints(p.Config).Raw,
+		ctx:         p.Context(),
+		local:       p.local,
+		concurrency: loaderConcurrency(p.Config),
 	}
-	return binaryFileNames, nil
+	return entries, bc, nil
 }
@@ -69,18 +69,18 @@ func (r *GitHubRepositoryMetadata) IsMFARequiredForAdministrativeActions() *bool
 	return r.ghOrg.TwoFactorRequirementEnabled
 }
 
-func loadRepositoryMetadata(ghClient *github.Client, owner, repo string) (ghRepo *github.Repository, data RepositoryMetadata, err error) {
-	repository, _, err := ghClient.Repositories.Get(context.Background(), owner, repo)
+func loadRepositoryMetadata(ctx context.Context, ghClient *github.Client, owner, repo string) (ghRepo *github.Repository, data RepositoryMetadata, err error) {
+	repository, _, err := ghClient.Repositories.Get(ctx, owner, repo)
 	if err != nil {
 		return repository, &GitHubRepositoryMetadata{}, err
 	}
-	organization, _, err := ghClient.Organizations.Get(context.Background(), owner)
+	organization, _, err := ghClient.Organizations.Get(ctx, owner)
 	if err != nil {
 		return repository, &GitHubRepositoryMetadata{
 			ghRepo: repository,
 		}, nil
 	}
-	branchRules, err := getRuleset(ghClient, owner, repo, repository.GetDefaultBranch())
+	branchRules, err := getRuleset(ctx, ghClient, owner, repo, repository.GetDefaultBranch())
 	if err != nil {
 		return repository, &GitHubRepositoryMetadata{
 			ghRepo: repository,
@@ -94,9 +94,9 @@ func loadRepositoryMetadata(ghClient *github.Client, owner, repo string) (ghRepo
 	}, nil
 }
 
-func getRuleset(ghClient *github.Client, owner, repo string, branchName string) (*github.BranchRules, error) {
+func getRuleset(ctx context.Context, ghClient *github.Client, owner, repo string, branchName string) (*github.BranchRules, error) {
 	branchRules, _, err := ghClient.Repositories.GetRulesForBranch(
-		context.Background(),
+		ctx,
 		owner,
 		repo,
 		branchName,
@@ -81,7 +81,7 @@ func TestLoadRepositoryMetadata(t *testing.T) {
 				testCase.responses...,
 			)
 			ghClient := github.NewClient(mockClient)
-			_, repoMetadata, err := loadRepositoryMetadata(ghClient, testCase.owner, testCase.repo)
+			_, repoMetadata, err := loadRepositoryMetadata(t.Context(), ghClient, testCase.owner, testCase.repo)
 			if testCase.expectedRepoError {
 				assert.Error(t, err)
 			} else {
@@ -3,10 +3,12 @@ package data
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gomarkdown/markdown"
@@ -14,6 +16,7 @@ import (
 	"github.com/google/go-github/v74/github"
 	"github.com/ossf/si-tooling/v2/si"
 	"github.com/privateerproj/privateer-sdk/config"
+	"golang.org/x/oauth2"
 )
 
 type HttpClient interface {
@@ -21,19 +24,19 @@ type HttpClient interface {
 }
 
 type RestData struct {
-	owner               string
-	repo                string
-	token               string
-	Config              *config.Config
-	WorkflowsEnabled    bool
-	WorkflowPermissions WorkflowPermissions
-	Insights            si.SecurityInsights
-	InsightsError       bool
-	Releases            []ReleaseData
-	Rulesets            []Ruleset
-	contents            RepoContent
-	ghClient            *github.Client `json:"-" yaml:"-"`
-	HttpClient          HttpClient     `json:"-" yaml:"-"`
+	owner         string
+	repo          string
+	tokens        oauth2.TokenSource
+	Config        *config.Config
+	Insights      si.SecurityInsights
+	InsightsError bool
+	contents      RepoContent
+	lazy          *restMemos
+	local         *localClone
+	ctx           context.Context
+	apiBase       string
+	ghClient      *github.Client `json:"-" yaml:"-"`
+	HttpClient    HttpClient     `json:"-" yaml:"-"`
 }
 
 type RepoContent struct {
@@ -60,38 +63,70 @@ type ReleaseData struct {
 
 type ReleaseAsset struct {
 	Name        string `json:"name"`
+	Size        int    `json:"size"`
 	DownloadURL string `json:"browser_download_url"`
 }
 
+type SecurityAdvisory struct {
+	GhsaId      string `json:"ghsa_id"`
+	CveId       string `json:"cve_id"`
+	Summary     string `json:"summary"`
+	Severity    string `json:"severity"`
+	URL         string `json:"html_url"`
+	PublishedAt string `json:"published_at"`
+}
+
 type WorkflowPermissions struct {
+	Enabled               bool   `json:"enabled"`
 	DefaultPermissions    string `json:"default_workflow_permissions"`
 	CanApprovePullRequest bool   `json:"can_approve_pull_request_reviews"`
 }
 
-var APIBase = "https://api.github.com"
+// SecretsPolicyFiles are searched in the root and forge directories for a policy on handling secrets
+var SecretsPolicyFiles = []string{"security.md", "contributing.md"}
+
+// DocsDirectories are the top level directories that conventionally hold project documentation
+var DocsDirectories = []string{"docs", "doc", "documentation"}
 
 func (r *RestData) Setup() error {
 	r.owner = r.Config.GetString("owner")
 	r.repo = r.Config.GetString("repo")
-	r.token = r.Config.GetString("token")
+	r.apiBase = configuredEndpoints(r.Config).API
 
+	// allocated before the payload is copied, so that every copy shares what is fetched later
+	r.memos()
+
+	// Security Insights is found through the contents. Everything else is fetched when a step first asks for it.
 	r.getRepoContents()
 	r.loadSecurityInsights()
-	_ = r.getWorkflowPermissions()
-	_ = r.getReleases()
 	return nil
 }
 
+// memos returns the lazily fetched data, which RestData built outside the Loader starts without
+func (r *RestData) memos() *restMemos {
+	if r.lazy == nil {
+		r.lazy = &restMemos{}
+	}
+	return r.lazy
+}
+
 func (r *RestData) MakeApiCall(endpoint string, isGithub bool) (body []byte, err error) {
 	if r.Config != nil && r.Config.Logger != nil {
 		r.Config.Logger.Trace(fmt.Sprintf("GET %s", endpoint))
 	}
-	request, err := http.NewRequest("GET", endpoint, nil)
+	if isGithub && r.local != nil {
+		return nil, ErrAPIUnavailable
+	}
+	request, err := http.NewRequestWithContext(r.context(), "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
-	if isGithub {
-		request.Header.Set("Authorization", "Bearer "+r.token)
+	if isGithub && r.tokens != nil {
+		token, err := r.tokens.Token()
+		if err != nil {
+			return nil, fmt.Errorf("error authenticating to GitHub: %w", err)
+		}
+		token.SetAuthHeader(request)
 	}
 	if r.HttpClient == nil {
 		r.HttpClient = &http.Client{}
@@ -108,8 +143,28 @@ func (r *RestData) MakeApiCall(endpoint string, isGithub bool) (body []byte, err
 	return io.ReadAll(response.Body)
 }
 
+// context returns the scan context, or a background context for data that was not built by the Loader
+func (r *RestData) context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// apiEndpoint returns the URL of a REST API path on the GitHub instance being scanned
+func (r *RestData) apiEndpoint(format string, args ...any) string {
+	base := r.apiBase
+	if base == "" {
+		base = APIBase
+	}
+	return base + "/" + fmt.Sprintf(format, args...)
+}
+
 func (r *RestData) getSourceFile(owner, repo, path string) (content *github.RepositoryContent, err error) {
-	content, _, _, err = r.ghClient.Repositories.GetContents(context.Background(), owner, repo, path, nil)
+	if r.local != nil {
+		return r.local.readFile(path)
+	}
+	content, _, _, err = r.ghClient.Repositories.GetContents(r.context(), owner, repo, path, nil)
 	if err != nil {
 		return
 	}
@@ -181,6 +236,23 @@ func (r *RestData) GetFileContent(path string) (content *github.RepositoryConten
 	return content, nil
 }
 
+// RepoFilePath returns the path of the file linked by a blob URL when the URL points into this repository
+// on the GitHub instance being scanned, or "" when the link points elsewhere
+func (r *RestData) RepoFilePath(link string) string {
+	prefix := fmt.Sprintf("%s/%s/%s/blob/", configuredEndpoints(r.Config).Web, r.owner, r.repo)
+	if len(link) <= len(prefix) || !strings.EqualFold(link[:len(prefix)], prefix) {
+		return ""
+	}
+	// the first path element after blob/ is the branch or commit
+	_, path, found := strings.Cut(link[len(prefix):], "/")
+	if !found {
+		return ""
+	}
+	path, _, _ = strings.Cut(path, "#")
+	path, _, _ = strings.Cut(path, "?")
+	return path
+}
+
 // returns true when a file with case insensitive name matching support.md is found in the root or forge directories or when the readme.md contains a heading named "Support"
 func (r *RestData) HasSupportMarkdown() bool {
 	if r.checkFile("support.md") != "" {
@@ -230,10 +302,148 @@ func parseMarkdownHeadings(content []byte) []string {
 	return headings
 }
 
+// MarkdownSection is the text under a markdown heading, up to the next heading of the same or higher level.
+// Text before the first heading is kept in a section with an empty Heading.
+type MarkdownSection struct {
+	Heading string
+	Body    string
+}
+
+// MarkdownDocument is a markdown file from the repository, parsed for the structures steps search through
+type MarkdownDocument struct {
+	Path         string
+	Headings     []string
+	Sections     []MarkdownSection
+	TableHeaders [][]string
+}
+
+// GetMarkdownDocument finds a markdown file by case insensitive name in the root or forge directory and parses it.
+// The returned document has an empty Path when the file is not found.
+func (r *RestData) GetMarkdownDocument(filename string) (document MarkdownDocument, err error) {
+	filepath := r.checkFile(filename)
+	if filepath == "" {
+		return document, nil
+	}
+	return r.GetMarkdownDocumentByPath(filepath)
+}
+
+// GetMarkdownDocumentByPath reads and parses the markdown file at path
+func (r *RestData) GetMarkdownDocumentByPath(path string) (document MarkdownDocument, err error) {
+	file, err := r.GetFileContent(path)
+	if err != nil {
+		return document, err
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return document, fmt.Errorf("failed to unpack contents of %s: %w", path, err)
+	}
+	return ParseMarkdownDocument(path, []byte(content)), nil
+}
+
+func ParseMarkdownDocument(path string, content []byte) MarkdownDocument {
+	return MarkdownDocument{
+		Path:         path,
+		Headings:     parseMarkdownHeadings(content),
+		Sections:     parseMarkdownSections(content),
+		TableHeaders: parseMarkdownTableHeaders(content),
+	}
+}
+
+// ContainsAny reports whether text contains any of the terms
+func ContainsAny(text string, terms []string) bool {
+	for _, term := range terms {
+		if strings.Contains(text, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseMarkdownSections(content []byte) (sections []MarkdownSection) {
+	md := markdown.Parse(content, nil)
+
+	// open holds the indexes and levels of the sections that the current block belongs to
+	var open []int
+	var levels []int
+	for _, child := range md.GetChildren() {
+		text := markdownText(child)
+		heading, ok := child.(*ast.Heading)
+		if !ok {
+			if len(sections) == 0 {
+				// text before the first heading belongs to an untitled section,
+				// which is closed by any heading since markdown headings stop at level 6
+				sections = append(sections, MarkdownSection{})
+				open = append(open, 0)
+				levels = append(levels, 7)
+			}
+			for _, i := range open {
+				sections[i].Body += text
+			}
+			continue
+		}
+		for len(levels) > 0 && levels[len(levels)-1] >= heading.Level {
+			open = open[:len(open)-1]
+			levels = levels[:len(levels)-1]
+		}
+		for _, i := range open {
+			sections[i].Body += text
+		}
+		sections = append(sections, MarkdownSection{Heading: strings.TrimSpace(text)})
+		open = append(open, len(sections)-1)
+		levels = append(levels, heading.Level)
+	}
+	return sections
+}
+
+func parseMarkdownTableHeaders(content []byte) (headers [][]string) {
+	md := markdown.Parse(content, nil)
+
+	ast.WalkFunc(md, func(node ast.Node, entering bool) ast.WalkStatus {
+		header, ok := node.(*ast.TableHeader)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		var cells []string
+		ast.WalkFunc(header, func(node ast.Node, entering bool) ast.WalkStatus {
+			if cell, ok := node.(*ast.TableCell); ok && entering {
+				cells = append(cells, strings.TrimSpace(markdownText(cell)))
+				return ast.SkipChildren
+			}
+			return ast.GoToNext
+		})
+		headers = append(headers, cells)
+		return ast.SkipChildren
+	})
+
+	return headers
+}
+
+// markdownText returns the text of a node and its children, with a line break after each block
+func markdownText(node ast.Node) string {
+	var text strings.Builder
+	ast.WalkFunc(node, func(node ast.Node, entering bool) ast.WalkStatus {
+		if leaf := node.AsLeaf(); leaf != nil && entering {
+			text.Write(leaf.Literal)
+		}
+		switch node.(type) {
+		case *ast.Paragraph, *ast.Heading, *ast.CodeBlock, *ast.TableRow:
+			if !entering {
+				text.WriteString("\n")
+			}
+		case *ast.TableCell:
+			if !entering {
+				text.WriteString(" ")
+			}
+		}
+		return ast.GoToNext
+	})
+	return text.String()
+}
+
 func (r *RestData) loadSecurityInsights() {
 	filepath := r.checkFile(si.SecurityInsightsFilename)
 	if filepath != "" {
-		insights, err := si.Read(r.owner, r.repo, filepath)
+		insights, err := r.readSecurityInsights(filepath)
 		r.Insights = insights
 		if err != nil {
 			r.Config.Logger.Error(fmt.Sprintf("failed to read security insights file: %s", err.Error()))
@@ -243,6 +453,24 @@ func (r *RestData) loadSecurityInsights() {
 	r.ensureInsightsInitialized()
 }
 
+// readSecurityInsights reads the Security Insights file through the same client as the other repository files,
+// so that it is found on GitHub Enterprise Server and in local clones
+func (r *RestData) readSecurityInsights(filepath string) (insights si.SecurityInsights, err error) {
+	file, err := r.getSourceFile(r.owner, r.repo, filepath)
+	if err != nil {
+		return insights, fmt.Errorf("error reading target SI: %w", err)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return insights, err
+	}
+	loaded, err := si.Load([]byte(content))
+	if err != nil {
+		return insights, err
+	}
+	return *loaded, nil
+}
+
 func (r *RestData) ensureInsightsInitialized() {
 	if r.Insights.Repository == nil {
 		r.Insights.Repository = &si.Repository{}
@@ -264,8 +492,69 @@ func (r *RestData) ensureInsightsInitialized() {
 	}
 }
 
+// secretsPolicyDocuments returns the markdown files that may describe how the project handles secrets:
+// SECURITY.md, CONTRIBUTING.md, security or secrets related files in the docs directories,
+// and the security policy from Security Insights when it links to a file in this repository.
+// They are parsed when first asked for.
+func (r *RestData) secretsPolicyDocuments() []MarkdownDocument {
+	documents, _ := r.memos().secretsPolicyDocs.get(func() ([]MarkdownDocument, error) {
+		return r.loadSecretsPolicyDocuments(), nil
+	})
+	return documents
+}
+
+func (r *RestData) loadSecretsPolicyDocuments() (documents []MarkdownDocument) {
+	var paths []string
+	for _, filename := range SecretsPolicyFiles {
+		if path := r.checkFile(filename); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	for _, dir := range DocsDirectories {
+		dirContents, err := r.contents.GetSubdirContentByPath(r, dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range dirContents.Content {
+			name := strings.ToLower(file.GetName())
+			if file.GetType() != "file" || !strings.HasSuffix(name, ".md") {
+				continue
+			}
+			if strings.Contains(name, "secret") || strings.Contains(name, "security") || strings.Contains(name, "credential") {
+				paths = append(paths, file.GetPath())
+			}
+		}
+	}
+	if link := r.Insights.Repository.Documentation.SecurityPolicy; link != nil {
+		path := r.RepoFilePath(string(*link))
+		if strings.HasSuffix(strings.ToLower(path), ".md") && !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+
+	for _, path := range paths {
+		document, err := r.GetMarkdownDocumentByPath(path)
+		if err != nil {
+			r.Config.Logger.Error(fmt.Sprintf("failed to read %s while searching for a secrets policy: %s", path, err.Error()))
+			continue
+		}
+		documents = append(documents, document)
+	}
+	return documents
+}
+
 func (r *RestData) getRepoContents() {
-	_, content, _, err := r.ghClient.Repositories.GetContents(context.Background(), r.owner, r.repo, "", nil)
+	if r.local != nil {
+		entries, err := r.Tree()
+		if err != nil {
+			r.Config.Logger.Error(fmt.Sprintf("failed to list top-level repo contents of local clone: %s", err.Error()))
+			return
+		}
+		r.contents.Content = listDirectory(entries, "")
+		r.contents.SubContent = make(map[string]RepoContent)
+		return
+	}
+	_, content, _, err := r.ghClient.Repositories.GetContents(r.context(), r.owner, r.repo, "", nil)
 	if err != nil {
 		r.Config.Logger.Error(fmt.Sprintf("failed to retrieve top-level repo contents via GitHub API: %s", err.Error()))
 		return
@@ -279,11 +568,15 @@ func (r *RestData) getRepoContents() {
 	r.Config.Logger.Trace(fmt.Sprintf("found %d top-level objects from GitHub API", len(r.contents.Content)))
 }
 
+// errDirectoryNotFound is returned by GetSubdirContentByPath when the repository has no directory at the path
+var errDirectoryNotFound = errors.New("not found")
+
 func (c *RepoContent) GetSubdirContentByPath(r *RestData, path string) (RepoContent, error) {
 	if c.SubContent == nil {
 		return RepoContent{}, fmt.Errorf("no subdirectories found")
 	}
 
+	contentsMu := &r.memos().contentsMu
 	parts := strings.Split(path, "/")
 	current := *c
 	currentPath := ""
@@ -297,7 +590,9 @@ func (c *RepoContent) GetSubdirContentByPath(r *RestData, path string) (RepoCont
 		}
 
 		// Check if we already have this subdirectory's content
+		contentsMu.RLock()
 		subdir, exists := current.SubContent[part]
+		contentsMu.RUnlock()
 		if !exists {
 			// Find this directory in the current level's content
 			var dirEntry *github.RepositoryContent
@@ -309,7 +604,7 @@ func (c *RepoContent) GetSubdirContentByPath(r *RestData, path string) (RepoCont
 			}
 
 			if dirEntry == nil {
-				return RepoContent{}, fmt.Errorf("directory '%s' not found in path '%s'", part, path)
+				return RepoContent{}, fmt.Errorf("directory '%s' %w in path '%s'", part, errDirectoryNotFound, path)
 			}
 
 			// Fetch the contents of this directory
@@ -319,8 +614,14 @@ func (c *RepoContent) GetSubdirContentByPath(r *RestData, path string) (RepoCont
 				return RepoContent{}, fmt.Errorf("failed to retrieve contents for %s: %w", dirEntry.GetPath(), err)
 			}
 
-			// Cache the result
-			current.SubContent[part] = subdir
+			// Cache the result, keeping the first copy when another caller fetched it at the same time
+			contentsMu.Lock()
+			if cached, exists := current.SubContent[part]; exists {
+				subdir = cached
+			} else {
+				current.SubContent[part] = subdir
+			}
+			contentsMu.Unlock()
 		}
 
 		// Move to the next level
@@ -337,10 +638,24 @@ func (c *RepoContent) GetSubdirContentByPath(r *RestData, path string) (RepoCont
 
 // getSubdirContents fetches contents of a directory
 func (r *RestData) getSubdirContents(path string) (RepoContent, error) {
-	if len(r.contents.SubContent[path].Content) > 0 {
-		return r.contents.SubContent[path], nil
+	contentsMu := &r.memos().contentsMu
+	contentsMu.RLock()
+	cached := r.contents.SubContent[path]
+	contentsMu.RUnlock()
+	if len(cached.Content) > 0 {
+		return cached, nil
+	}
+	if r.local != nil {
+		entries, err := r.Tree()
+		if err != nil {
+			return RepoContent{}, err
+		}
+		return RepoContent{
+			Content:    listDirectory(entries, path),
+			SubContent: make(map[string]RepoContent),
+		}, nil
 	}
-	_, content, _, err := r.ghClient.Repositories.GetContents(context.Background(), r.owner, r.repo, path, nil)
+	_, content, _, err := r.ghClient.Repositories.GetContents(r.context(), r.owner, r.repo, path, nil)
 	if err != nil {
 		return RepoContent{}, err
 	}
@@ -351,60 +666,100 @@ func (r *RestData) getSubdirContents(path string) (RepoContent, error) {
 	}, nil
 }
 
-func (r *RestData) getReleases() error {
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/releases", APIBase, r.owner, r.repo)
-	responseData, err := r.MakeApiCall(endpoint, true)
-	if err != nil {
-		return err
-	}
-	return json.Unmarshal(responseData, &r.Releases)
+// Releases returns the repository's releases, newest first. A local clone lists its tags instead.
+func (r *RestData) Releases() ([]ReleaseData, error) {
+	return r.memos().releases.get(func() (releases []ReleaseData, err error) {
+		if r.local != nil {
+			return r.local.releases()
+		}
+		endpoint := r.apiEndpoint("repos/%s/%s/releases", r.owner, r.repo)
+		responseData, err := r.MakeApiCall(endpoint, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve releases: %w", err)
+		}
+		err = json.Unmarshal(responseData, &releases)
+		return releases, err
+	})
 }
 
-func (r *RestData) getWorkflowPermissions() error {
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/actions", APIBase, r.owner, r.repo)
-	responseData, err := r.MakeApiCall(endpoint, true)
-	if err != nil {
-		return err
-	}
-	var actionsData struct {
-		Enabled bool `json:"enabled"`
-	}
-	if err := json.Unmarshal(responseData, &actionsData); err != nil {
-		return fmt.Errorf("failed to parse actions data: %v", err)
-	}
-	r.WorkflowsEnabled = actionsData.Enabled
+// SecurityAdvisories returns the repository's published GitHub Security Advisories
+func (r *RestData) SecurityAdvisories() ([]SecurityAdvisory, error) {
+	return r.memos().securityAdvisories.get(func() (advisories []SecurityAdvisory, err error) {
+		endpoint := r.apiEndpoint("repos/%s/%s/security-advisories?state=published", r.owner, r.repo)
+		responseData, err := r.MakeApiCall(endpoint, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve security advisories: %w", err)
+		}
+		err = json.Unmarshal(responseData, &advisories)
+		return advisories, err
+	})
+}
 
-	endpoint = fmt.Sprintf("%s/repos/%s/%s/actions/permissions/workflow", APIBase, r.owner, r.repo)
-	responseData, err = r.MakeApiCall(endpoint, true)
-	if err != nil {
-		return err
-	}
-	if err := json.Unmarshal(responseData, &r.WorkflowPermissions); err != nil {
-		return fmt.Errorf("failed to parse permissions: %v", err)
-	}
-	return err
+// WorkflowPermissions returns whether GitHub Actions is enabled and the default permissions of its workflows
+func (r *RestData) WorkflowPermissions() (WorkflowPermissions, error) {
+	return r.memos().workflowPermissions.get(func() (permissions WorkflowPermissions, err error) {
+		endpoint := r.apiEndpoint("repos/%s/%s/actions", r.owner, r.repo)
+		responseData, err := r.MakeApiCall(endpoint, true)
+		if err != nil {
+			return permissions, err
+		}
+		if err := json.Unmarshal(responseData, &permissions); err != nil {
+			return permissions, fmt.Errorf("failed to parse actions data: %v", err)
+		}
+
+		endpoint = r.apiEndpoint("repos/%s/%s/actions/permissions/workflow", r.owner, r.repo)
+		responseData, err = r.MakeApiCall(endpoint, true)
+		if err != nil {
+			return permissions, err
+		}
+		if err := json.Unmarshal(responseData, &permissions); err != nil {
+			return permissions, fmt.Errorf("failed to parse permissions: %v", err)
+		}
+		return permissions, nil
+	})
 }
 
-func (r *RestData) GetRulesets(branchName string) []Ruleset {
-	endpoint := fmt.Sprintf("%s/repos/%s/%s/rules/branches/%s", APIBase, r.owner, r.repo, branchName)
-	responseData, err := r.MakeApiCall(endpoint, true)
-	if err != nil {
-		r.Config.Logger.Error(fmt.Sprintf("error getting rulesets: %s", err.Error()))
-	}
+// Rulesets returns the rulesets that apply to a branch
+func (r *RestData) Rulesets(branchName string) ([]Ruleset, error) {
+	return r.memos().branchRulesets(branchName).get(func() (rulesets []Ruleset, err error) {
+		endpoint := r.apiEndpoint("repos/%s/%s/rules/branches/%s", r.owner, r.repo, branchName)
+		responseData, err := r.MakeApiCall(endpoint, true)
+		if err != nil {
+			return nil, fmt.Errorf("error getting rulesets: %w", err)
+		}
+		err = json.Unmarshal(responseData, &rulesets)
+		return rulesets, err
+	})
+}
 
-	_ = json.Unmarshal(responseData, &r.Rulesets)
-	return r.Rulesets
+// Workflows returns the GitHub Actions workflow files in the .github/workflows directory with their contents.
+// A repository without that directory has no workflows, which is not an error.
+func (r *RestData) Workflows() ([]*github.RepositoryContent, error) {
+	return r.memos().workflows.get(func() ([]*github.RepositoryContent, error) {
+		workflows, err := r.GetDirectoryContent(".github/workflows")
+		if errors.Is(err, errDirectoryNotFound) {
+			return nil, nil
+		}
+		return workflows, err
+	})
 }
 
-// IsCodeRepo returns true if the repository contains any programming languages.
+// IsCodeRepo returns true if the repository contains any programming languages. The languages are listed when
+// first asked for.
 //
 // TODO: Consider using GitHub Linguist metadata (https://github.com/github-linguist/linguist/blob/main/lib/linguist/languages.yml)
 // to distinguish between programming, markup, data, and prose content types for more nuanced
 // repository classification.
 func (r *RestData) IsCodeRepo() (bool, error) {
-	languages, _, err := r.ghClient.Repositories.ListLanguages(context.Background(), r.owner, r.repo)
-	if err != nil {
-		return false, err
-	}
-	return len(languages) > 0, nil
+	return r.memos().isCodeRepo.get(func() (bool, error) {
+		if r.local != nil {
+			entries, err := r.Tree()
+			return isCodeTree(entries), err
+		}
+		languages, _, err := r.ghClient.Repositories.ListLanguages(r.context(), r.owner, r.repo)
+		if err != nil {
+			return false, err
+		}
+		return len(languages) > 0, nil
+	})
 }
@@ -1,11 +1,14 @@
 package data
 
 import (
+	"io"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/google/go-github/v74/github"
 	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/privateerproj/privateer-sdk/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -115,7 +118,7 @@ func TestGetSubdirContentByPath(t *testing.T) {
 
 	t.Run("nonexistent path", func(t *testing.T) {
 		_, err := root.GetSubdirContentByPath(restData, ".github/nonexistent")
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, errDirectoryNotFound)
 		assert.Contains(t, err.Error(), "directory 'nonexistent' not found")
 	})
 
@@ -190,3 +193,164 @@ func TestIsCodeRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestSecurityAdvisories(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		statusCode    int
+		expectedCount int
+		expectedError bool
+	}{
+		{
+			name:          "published advisories",
+			body:          `[{"ghsa_id": "GHSA-xxxx-yyyy-zzzz", "cve_id": "CVE-2024-0001", "severity": "high", "html_url": "https://github.com/org/repo/security/advisories/GHSA-xxxx-yyyy-zzzz"}]`,
+			statusCode:    http.StatusOK,
+			expectedCount: 1,
+		},
+		{
+			name:          "no advisories",
+			body:          `[]`,
+			statusCode:    http.StatusOK,
+			expectedCount: 0,
+		},
+		{
+			name:          "api error",
+			body:          `{"message": "Not Found"}`,
+			statusCode:    http.StatusNotFound,
+			expectedCount: 0,
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rest := &RestData{
+				owner: "test-owner",
+				repo:  "test-repo",
+				HttpClient: &ClientMock{
+					Response: &http.Response{
+						StatusCode: tt.statusCode,
+						Status:     http.StatusText(tt.statusCode),
+						Body:       io.NopCloser(strings.NewReader(tt.body)),
+					},
+				},
+			}
+			advisories, err := rest.SecurityAdvisories()
+			assert.Equal(t, tt.expectedCount, len(advisories))
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseMarkdownDocument(t *testing.T) {
+	content := `Preamble text.
+
+# Project
+
+Intro text.
+
+## Supported Versions
+
+Only the latest minor release receives fixes.
+
+| Version | Supported          |
+| ------- | ------------------ |
+| 2.x     | yes                |
+| 1.x     | no                 |
+
+### Older releases
+
+Unsupported.
+
+## Reporting a Vulnerability
+
+Email us.
+`
+	document := ParseMarkdownDocument("SECURITY.md", []byte(content))
+
+	assert.Equal(t, "SECURITY.md", document.Path)
+	assert.Equal(t, []string{"Project", "Supported Versions", "Older releases", "Reporting a Vulnerability"}, document.Headings)
+	assert.Equal(t, [][]string{{"Version", "Supported"}}, document.TableHeaders)
+
+	if assert.Len(t, document.Sections, 5) {
+		assert.Equal(t, MarkdownSection{Heading: "", Body: "Preamble text.\n"}, document.Sections[0])
+		document.Sections = document.Sections[1:]
+		supported := document.Sections[1]
+		assert.Equal(t, "Supported Versions", supported.Heading)
+		assert.Contains(t, supported.Body, "Only the latest minor release receives fixes.")
+		assert.Contains(t, supported.Body, "Older releases")
+		assert.Contains(t, supported.Body, "Unsupported.")
+		assert.NotContains(t, supported.Body, "Email us.")
+
+		assert.Contains(t, document.Sections[0].Body, "Email us.")
+		assert.Equal(t, "Email us.\n", document.Sections[3].Body)
+	}
+}
+
+func TestRepoFilePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		link     string
+		expected string
+	}{
+		{
+			name:     "file in this repository",
+			link:     "https://github.com/test-owner/test-repo/blob/main/GOVERNANCE.md",
+			expected: "GOVERNANCE.md",
+		},
+		{
+			name:     "nested file with anchor",
+			link:     "https://github.com/Test-Owner/test-repo/blob/v1.2.0/docs/governance.md#maintainers",
+			expected: "docs/governance.md",
+		},
+		{
+			name:     "file in another repository",
+			link:     "https://github.com/test-owner/community/blob/main/GOVERNANCE.md",
+			expected: "",
+		},
+		{
+			name:     "not a blob link",
+			link:     "https://example.com/governance",
+			expected: "",
+		},
+		{
+			name:     "blob link without a path",
+			link:     "https://github.com/test-owner/test-repo/blob/main",
+			expected: "",
+		},
+	}
+
+	rest := &RestData{owner: "test-owner", repo: "test-repo"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rest.RepoFilePath(tt.link))
+		})
+	}
+
+	t.Run("enterprise server links", func(t *testing.T) {
+		ghes := &RestData{
+			owner:  "test-owner",
+			repo:   "test-repo",
+			Config: &config.Config{Vars: map[string]any{"api-url": "https://ghes.example.com/api/v3"}},
+		}
+		assert.Equal(t, "docs/governance.md", ghes.RepoFilePath("https://ghes.example.com/test-owner/test-repo/blob/main/docs/governance.md"))
+		assert.Equal(t, "", ghes.RepoFilePath("https://github.com/test-owner/test-repo/blob/main/docs/governance.md"))
+	})
+}
+
+func TestWorkflowsWithoutWorkflowsDirectory(t *testing.T) {
+	rest := &RestData{
+		contents: RepoContent{
+			Content:    []*github.RepositoryContent{{Name: github.Ptr("README.md"), Type: github.Ptr("file"), Path: github.Ptr("README.md")}},
+			SubContent: map[string]RepoContent{},
+		},
+	}
+	workflows, err := rest.Workflows()
+	assert.NoError(t, err, "a repository without a workflows directory has no workflows")
+	assert.Empty(t, workflows)
+}
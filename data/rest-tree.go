@@ -0,0 +1,86 @@
+package data
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// RepoTreeEntry is a file ("blob"), directory ("tree") or submodule ("commit") in the repository tree
+type RepoTreeEntry struct {
+	Path string
+	Type string
+	Size int
+}
+
+// Tree returns every entry on the default branch with its full path and size.
+// The whole tree is requested at once, and any subtree GitHub truncates is walked
+// one level at a time instead. A local clone lists its tracked files instead.
+func (r *RestData) Tree() ([]RepoTreeEntry, error) {
+	return r.memos().tree.get(func() (entries []RepoTreeEntry, err error) {
+		if r.local != nil {
+			entries, err = r.local.tree()
+		} else {
+			entries, err = r.walkTree("HEAD", "")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entries == nil {
+			entries = []RepoTreeEntry{}
+		}
+		return entries, nil
+	})
+}
+
+// walkTree lists the tree at sha recursively, prefixing every path with the tree's location
+func (r *RestData) walkTree(sha string, prefix string) (entries []RepoTreeEntry, err error) {
+	tree, _, err := r.ghClient.Git.GetTree(r.context(), r.owner, r.repo, sha, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve repository tree at '%s': %w", treeLocation(prefix), err)
+	}
+	if !tree.GetTruncated() {
+		for _, entry := range tree.Entries {
+			entries = append(entries, newRepoTreeEntry(prefix, entry))
+		}
+		return entries, nil
+	}
+
+	// The recursive listing was cut short, so list this level alone and walk each subtree separately
+	tree, _, err = r.ghClient.Git.GetTree(r.context(), r.owner, r.repo, sha, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve repository tree at '%s': %w", treeLocation(prefix), err)
+	}
+	if tree.GetTruncated() && r.Config != nil {
+		r.Config.Logger.Warn(fmt.Sprintf("repository tree at '%s' has too many entries to list completely", treeLocation(prefix)))
+	}
+	for _, entry := range tree.Entries {
+		treeEntry := newRepoTreeEntry(prefix, entry)
+		entries = append(entries, treeEntry)
+		if treeEntry.Type != "tree" {
+			continue
+		}
+		subEntries, err := r.walkTree(entry.GetSHA(), treeEntry.Path)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, subEntries...)
+	}
+	return entries, nil
+}
+
+func newRepoTreeEntry(prefix string, entry *github.TreeEntry) RepoTreeEntry {
+	return RepoTreeEntry{
+		Path: path.Join(prefix, entry.GetPath()),
+		Type: entry.GetType(),
+		Size: entry.GetSize(),
+	}
+}
+
+func treeLocation(prefix string) string {
+	if prefix == "" {
+		return "/"
+	}
+	return prefix
+}
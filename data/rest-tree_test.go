@@ -0,0 +1,119 @@
+package data
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/migueleliasweb/go-github-mock/src/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// treeResponses maps a tree SHA and whether the request was recursive to the tree GitHub returns
+type treeResponses map[string]map[bool]github.Tree
+
+func newTreeRestData(t *testing.T, responses treeResponses) *RestData {
+	mockClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetReposGitTreesByOwnerByRepoByTreeSha,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tree, ok := responses[path.Base(r.URL.Path)][r.URL.Query().Get("recursive") != ""]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+					return
+				}
+				body, err := json.Marshal(tree)
+				if err != nil {
+					t.Fatal(err)
+				}
+				_, _ = w.Write(body)
+			}),
+		),
+	)
+	return &RestData{
+		owner:    "test-owner",
+		repo:     "test-repo",
+		ghClient: github.NewClient(mockClient),
+	}
+}
+
+func treeEntry(entryPath, entryType, sha string, size int) *github.TreeEntry {
+	entry := &github.TreeEntry{Path: github.Ptr(entryPath), Type: github.Ptr(entryType), SHA: github.Ptr(sha)}
+	if entryType == "blob" {
+		entry.Size = github.Ptr(size)
+	}
+	return entry
+}
+
+func TestTree(t *testing.T) {
+	t.Run("complete recursive listing", func(t *testing.T) {
+		rest := newTreeRestData(t, treeResponses{
+			"HEAD": {true: {Entries: []*github.TreeEntry{
+				treeEntry("README.md", "blob", "a", 12),
+				treeEntry("cmd", "tree", "b", 0),
+				treeEntry("cmd/main.go", "blob", "c", 40),
+			}}},
+		})
+
+		entries, err := rest.Tree()
+		assert.NoError(t, err)
+		assert.Equal(t, []RepoTreeEntry{
+			{Path: "README.md", Type: "blob", Size: 12},
+			{Path: "cmd", Type: "tree"},
+			{Path: "cmd/main.go", Type: "blob", Size: 40},
+		}, entries)
+	})
+
+	t.Run("truncated listing is walked per subtree", func(t *testing.T) {
+		rest := newTreeRestData(t, treeResponses{
+			"HEAD": {
+				true: {Truncated: github.Ptr(true), Entries: []*github.TreeEntry{
+					treeEntry("README.md", "blob", "a", 12),
+				}},
+				false: {Entries: []*github.TreeEntry{
+					treeEntry("README.md", "blob", "a", 12),
+					treeEntry("services", "tree", "s", 0),
+				}},
+			},
+			"s": {
+				true: {Truncated: github.Ptr(true)},
+				false: {Entries: []*github.TreeEntry{
+					treeEntry("payments", "tree", "p", 0),
+				}},
+			},
+			"p": {true: {Entries: []*github.TreeEntry{
+				treeEntry("a/b/c/d", "tree", "d", 0),
+				treeEntry("a/b/c/d/helper.so", "blob", "e", 2048),
+			}}},
+		})
+
+		entries, err := rest.Tree()
+		assert.NoError(t, err)
+		assert.Equal(t, []RepoTreeEntry{
+			{Path: "README.md", Type: "blob", Size: 12},
+			{Path: "services", Type: "tree"},
+			{Path: "services/payments", Type: "tree"},
+			{Path: "services/payments/a/b/c/d", Type: "tree"},
+			{Path: "services/payments/a/b/c/d/helper.so", Type: "blob", Size: 2048},
+		}, entries)
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		rest := newTreeRestData(t, treeResponses{})
+
+		_, err := rest.Tree()
+		assert.Error(t, err)
+	})
+
+	t.Run("result is cached", func(t *testing.T) {
+		rest := &RestData{}
+		rest.memos().tree.set([]RepoTreeEntry{{Path: "README.md", Type: "blob"}}, nil)
+
+		entries, err := rest.Tree()
+		assert.NoError(t, err)
+		assert.Equal(t, []RepoTreeEntry{{Path: "README.md", Type: "blob"}}, entries)
+	})
+}
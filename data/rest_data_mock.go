@@ -2,8 +2,11 @@ package data
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
+
+	"github.com/google/go-github/v74/github"
 )
 
 type ClientMock struct {
@@ -33,3 +36,111 @@ func NewPayloadWithHTTPMock(base Payload, body []byte, statusCode int, httpErr e
 	base.HttpClient = mock
 	return base
 }
+
+// NewPayloadWithTree returns a copy of base whose repository tree is already loaded with entries
+func NewPayloadWithTree(base Payload, entries []RepoTreeEntry) Payload {
+	if entries == nil {
+		entries = []RepoTreeEntry{}
+	}
+	return withRestData(base, func(rest *RestData) {
+		rest.memos().tree.set(entries, nil)
+	})
+}
+
+// NewPayloadWithTreeError returns a copy of base whose repository tree failed to load with err
+func NewPayloadWithTreeError(base Payload, err error) Payload {
+	return withRestData(base, func(rest *RestData) {
+		rest.memos().tree.set(nil, err)
+	})
+}
+
+// NewPayloadWithReleases returns a copy of base whose releases are already loaded
+func NewPayloadWithReleases(base Payload, releases []ReleaseData) Payload {
+	return withRestData(base, func(rest *RestData) {
+		rest.memos().releases.set(releases, nil)
+	})
+}
+
+// NewPayloadWithSecurityAdvisories returns a copy of base whose security advisories are already loaded
+func NewPayloadWithSecurityAdvisories(base Payload, advisories []SecurityAdvisory) Payload {
+	return withRestData(base, func(rest *RestData) {
+		rest.memos().securityAdvisories.set(advisories, nil)
+	})
+}
+
+// NewPayloadWithSecurityAdvisoriesError returns a copy of base whose security advisories failed to load with err
+func NewPayloadWithSecurityAdvisoriesError(base Payload, err error) Payload {
+	return withRestData(base, func(rest *RestData) {
+		rest.memos().securityAdvisories.set(nil, err)
+	})
+}
+
+// NewPayloadWithWorkflowPermissions returns a copy of base whose workflow permissions are already loaded
+func NewPayloadWithWorkflowPermissions(base Payload, permissions WorkflowPermissions) Payload {
+	return withRestData(base, func(rest *RestData) {
+		rest.memos().workflowPermissions.set(permissions, nil)
+	})
+}
+
+// NewPayloadWithWorkflows returns a copy of base whose workflow files are already loaded
+func NewPayloadWithWorkflows(base Payload, workflows []*github.RepositoryContent) Payload {
+	return withRestData(base, func(rest *RestData) {
+		rest.memos().workflows.set(workflows, nil)
+	})
+}
+
+// NewPayloadWithWorkflowsError returns a copy of base whose workflow files failed to load with err
+func NewPayloadWithWorkflowsError(base Payload, err error) Payload {
+	return withRestData(base, func(rest *RestData) {
+		rest.memos().workflows.set(nil, err)
+	})
+}
+
+// NewPayloadWithCodeRepo returns a copy of base whose repository languages are already loaded,
+// showing whether it contains code
+func NewPayloadWithCodeRepo(base Payload, isCodeRepo bool) Payload {
+	return withRestData(base, func(rest *RestData) {
+		rest.memos().isCodeRepo.set(isCodeRepo, nil)
+	})
+}
+
+// NewPayloadWithDependencyManifests returns a copy of base whose dependency graph is already loaded with manifests
+func NewPayloadWithDependencyManifests(base Payload, manifests []ManifestNode) Payload {
+	base.fetched = &payloadMemos{}
+	base.fetched.dependencyManifests.set(dependencyManifests{count: len(manifests), manifests: manifests}, nil)
+	return base
+}
+
+// NewPayloadWithSuspectedBinaries returns a copy of base whose binary check has already run,
+// finding the binaries and leaving the unchecked files undecided
+func NewPayloadWithSuspectedBinaries(base Payload, binaries, unchecked []string) Payload {
+	base.fetched = &payloadMemos{}
+	base.fetched.suspectedBinaryPaths.set(binaryScan{binaries: binaries, unchecked: unchecked}, nil)
+	return base
+}
+
+// NewPayloadWithSecurityPosture returns a copy of base whose security posture is already built
+func NewPayloadWithSecurityPosture(base Payload, posture SecurityPosture) Payload {
+	base.fetched = &payloadMemos{}
+	base.fetched.securityPosture.set(posture, nil)
+	return base
+}
+
+// NewPayloadWithContext returns a copy of base that was loaded within the scan context ctx
+func NewPayloadWithContext(base Payload, ctx context.Context) Payload {
+	return withRestData(base, func(rest *RestData) {
+		rest.ctx = ctx
+	})
+}
+
+// withRestData returns a copy of base with a copy of its RestData changed by update.
+// The copy shares any data base has already fetched.
+func withRestData(base Payload, update func(rest *RestData)) Payload {
+	rest := RestData{}
+	if base.RestData != nil {
+		rest = *base.RestData
+	}
+	update(&rest)
+	base.RestData = &rest
+	return base
+}
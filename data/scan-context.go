@@ -0,0 +1,52 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/privateerproj/privateer-sdk/config"
+)
+
+// newScanContext returns the context every request of a scan is made with. It is cancelled when the process
+// is interrupted or terminated, or once the timeout var has passed, and its cause explains which. After the
+// first signal the default handling is restored, so a second interrupt stops the scan immediately.
+// The signal handler and the timeout are released as soon as the context is done, so the stop func, which
+// cancels the context, only needs to be called by callers that finish with a scan before the process exits.
+func newScanContext(cfg *config.Config) (ctx context.Context, stop func(), err error) {
+	var timeout time.Duration
+	if value := cfg.GetString("timeout"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid timeout %s: %w", value, err)
+		}
+		timeout = parsed
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(signals)
+		select {
+		case received := <-signals:
+			cancel(fmt.Errorf("scan cancelled by %s signal", received))
+		case <-ctx.Done():
+		}
+	}()
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			cancel(fmt.Errorf("scan cancelled after the %s timeout", timeout))
+		})
+		context.AfterFunc(ctx, func() { timer.Stop() })
+	}
+	stop = func() { cancel(errScanStopped) }
+	return ctx, stop, nil
+}
+
+// errScanStopped is the cause of a scan context that was released after the scan
+var errScanStopped = errors.New("scan finished")
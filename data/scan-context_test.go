@@ -0,0 +1,68 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/privateerproj/privateer-sdk/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScanContext(t *testing.T) {
+	ctx, stop, err := newScanContext(&config.Config{Vars: map[string]any{}})
+	require.NoError(t, err)
+	assert.NoError(t, ctx.Err(), "without a timeout the scan runs until it is interrupted")
+	stop()
+	assert.ErrorIs(t, context.Cause(ctx), errScanStopped)
+
+	ctx, stop, err = newScanContext(&config.Config{Vars: map[string]any{"timeout": "10ms"}})
+	require.NoError(t, err)
+	defer stop()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("scan context was not cancelled after the timeout")
+	}
+	assert.EqualError(t, context.Cause(ctx), "scan cancelled after the 10ms timeout")
+
+	_, _, err = newScanContext(&config.Config{Vars: map[string]any{"timeout": "ten minutes"}})
+	assert.EqualError(t, err, `invalid timeout ten minutes: time: invalid duration "ten minutes"`)
+}
+
+func TestPayloadCloseStopsScanContext(t *testing.T) {
+	ctx, stop, err := newScanContext(&config.Config{Vars: map[string]any{"timeout": "1h"}})
+	require.NoError(t, err)
+	payload := Payload{RestData: &RestData{ctx: ctx}, stop: stop}
+
+	payload.Close()
+	payload.Close()
+	assert.ErrorIs(t, context.Cause(payload.Context()), errScanStopped)
+	assert.NotPanics(t, func() { (&Payload{}).Close() }, "payloads built outside the loaders have nothing to release")
+}
+
+func TestCancelledScanStopsRequests(t *testing.T) {
+	requested := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		<-requested
+		cancel(errors.New("scan cancelled by interrupt signal"))
+	}()
+	restData := &RestData{ctx: ctx, HttpClient: server.Client()}
+
+	_, err := restData.MakeApiCall(server.URL+"/repos/owner/repo/releases", true)
+	assert.ErrorContains(t, err, "scan cancelled by interrupt signal", "the in-flight request is cancelled rather than left hanging")
+
+	payload := Payload{RestData: restData}
+	assert.EqualError(t, context.Cause(payload.Context()), "scan cancelled by interrupt signal")
+}
@@ -1,15 +1,54 @@
 package data
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/google/go-github/v74/github"
 	"github.com/ossf/si-tooling/v2/si"
 )
 
+// The topics a policy for handling secrets is expected to cover
+const (
+	SecretsTopicStoring   = "storing"
+	SecretsTopicAccessing = "accessing"
+	SecretsTopicRotating  = "rotating"
+)
+
+// SecretsTopics lists every topic a policy for handling secrets is expected to cover, in reporting order
+var SecretsTopics = []string{SecretsTopicStoring, SecretsTopicAccessing, SecretsTopicRotating}
+
+// secretsHeadingTerms identify a section heading about secrets or credentials
+var secretsHeadingTerms = []string{"secret", "credential", "api key", "signing key", "token", "password"}
+
+// secretsTopicTerms identify which topic a secrets section covers
+var secretsTopicTerms = map[string][]string{
+	SecretsTopicStoring:   {"store", "storing", "storage", "stored", "vault", "secret manager", "secrets manager", "encrypted"},
+	SecretsTopicAccessing: {"access", "permission", "who can", "least privilege", "restricted to", "granted"},
+	SecretsTopicRotating:  {"rotat", "revok", "revoc", "expir", "renew"},
+}
+
+// Where a policy for handling secrets was found
+const (
+	SecretsPolicySourceDocument = "document"
+	SecretsPolicySourceInsights = "insights"
+)
+
+// SecretsPolicy describes where a policy for handling secrets was found and which topics it covers.
+// Source is SecretsPolicySourceDocument when Location is a repository file whose sections were searched for
+// topics, and SecretsPolicySourceInsights when Location is a Security Insights link that could not be searched.
+type SecretsPolicy struct {
+	Source   string
+	Location string
+	Topics   []string
+}
+
 // SecurityPosture defines an interface for accessing security-related metadata about a repository.
 type SecurityPosture interface {
 	PreventsPushingSecrets() bool
 	ScansForSecrets() bool
 	DefinesPolicyForHandlingSecrets() bool
+	SecretsPolicy() SecretsPolicy
 }
 
 type RepoSecurityPosture struct {
@@ -17,26 +56,98 @@ type RepoSecurityPosture struct {
 	preventsSecretPushing           bool
 	scansForSecrets                 bool
 	definesPolicyForHandlingSecrets bool
+	secretsPolicy                   SecretsPolicy
 }
 
 func buildSecurityPosture(repository *github.Repository, rd RestData) (SecurityPosture, error) {
+	secretsPolicy := findSecretsPolicy(rd.secretsPolicyDocuments(), rd.Insights)
 	securityConfig := repository.GetSecurityAndAnalysis()
 	if securityConfig == nil {
 		return &RepoSecurityPosture{
-			restData: rd,
+			restData:                        rd,
+			definesPolicyForHandlingSecrets: secretsPolicy.Location != "",
+			secretsPolicy:                   secretsPolicy,
 		}, nil
 	}
 	secretsScanningStatus := securityConfig.GetSecretScanning().GetStatus()
 	insightsClaimsSecretsTooling := insightsClaimsSecretsTooling(rd.Insights)
 	return &RepoSecurityPosture{
-		restData:              rd,
-		preventsSecretPushing: secretsScanningStatus == "enabled" || insightsClaimsSecretsTooling,
-		scansForSecrets:       secretsScanningStatus == "enabled" || insightsClaimsSecretsTooling,
-		// TODO: consider if SecurityInsights should have a policy doc field in ProjectDocumentation to handle this
-		// definesPolicyForHandlingSecrets: rd.SecurityInsights != nil && ....
+		restData:                        rd,
+		preventsSecretPushing:           secretsScanningStatus == "enabled" || insightsClaimsSecretsTooling,
+		scansForSecrets:                 secretsScanningStatus == "enabled" || insightsClaimsSecretsTooling,
+		definesPolicyForHandlingSecrets: secretsPolicy.Location != "",
+		secretsPolicy:                   secretsPolicy,
 	}, nil
 }
 
+// findSecretsPolicy searches the sections of the given documents whose headings mention secrets for the topics they cover.
+// Security Insights has no dedicated field for a secrets policy, so a documentation link that names secrets is accepted
+// when no section is found in the repository.
+func findSecretsPolicy(documents []MarkdownDocument, insights si.SecurityInsights) (policy SecretsPolicy) {
+	for _, document := range documents {
+		var topics []string
+		for _, section := range document.Sections {
+			heading := strings.ToLower(section.Heading)
+			if !ContainsAny(heading, secretsHeadingTerms) {
+				continue
+			}
+			text := heading + "\n" + strings.ToLower(section.Body)
+			for _, topic := range SecretsTopics {
+				if !slices.Contains(topics, topic) && ContainsAny(text, secretsTopicTerms[topic]) {
+					topics = append(topics, topic)
+				}
+			}
+			if policy.Location == "" {
+				policy.Source = SecretsPolicySourceDocument
+				policy.Location = document.Path
+			}
+		}
+		if policy.Location == document.Path {
+			policy.Topics = orderedTopics(topics)
+			return policy
+		}
+	}
+
+	for _, link := range insightsDocumentationLinks(insights) {
+		if strings.Contains(strings.ToLower(link), "secret") {
+			return SecretsPolicy{Source: SecretsPolicySourceInsights, Location: link}
+		}
+	}
+	return policy
+}
+
+func insightsDocumentationLinks(insights si.SecurityInsights) (links []string) {
+	addLink := func(link *si.URL) {
+		if link != nil {
+			links = append(links, string(*link))
+		}
+	}
+	if insights.Repository != nil && insights.Repository.Documentation != nil {
+		documentation := insights.Repository.Documentation
+		addLink(documentation.SecurityPolicy)
+		addLink(documentation.ContributingGuide)
+		addLink(documentation.Governance)
+		addLink(documentation.ReviewPolicy)
+		addLink(documentation.DependencyManagementPolicy)
+	}
+	if insights.Project != nil && insights.Project.Documentation != nil {
+		documentation := insights.Project.Documentation
+		addLink(documentation.DetailedGuide)
+		addLink(documentation.ReleaseProcess)
+		addLink(documentation.SupportPolicy)
+	}
+	return links
+}
+
+func orderedTopics(found []string) (topics []string) {
+	for _, topic := range SecretsTopics {
+		if slices.Contains(found, topic) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
 func insightsClaimsSecretsTooling(insights si.SecurityInsights) bool {
 	if insights.Repository.SecurityPosture.Tools == nil {
 		return false
@@ -60,3 +171,7 @@ func (rsp *RepoSecurityPosture) ScansForSecrets() bool {
 func (rsp *RepoSecurityPosture) DefinesPolicyForHandlingSecrets() bool {
 	return rsp.definesPolicyForHandlingSecrets
 }
+
+func (rsp *RepoSecurityPosture) SecretsPolicy() SecretsPolicy {
+	return rsp.secretsPolicy
+}
@@ -23,6 +23,7 @@ func TestRepoSecurityPostureMethods(t *testing.T) {
 func TestBuildSecurityPosture_NoSecurityConfig(t *testing.T) {
 	repo := &github.Repository{}
 	rd := RestData{}
+	rd.memos().secretsPolicyDocs.set(nil, nil)
 	sp, err := buildSecurityPosture(repo, rd)
 	assert.NoError(t, err)
 	assert.NotNil(t, sp)
@@ -44,6 +45,7 @@ func TestBuildSecurityPosture_SecretScanningEnabled(t *testing.T) {
 			Repository: &si.Repository{},
 		},
 	}
+	rd.memos().secretsPolicyDocs.set(nil, nil)
 	sp, err := buildSecurityPosture(repo, rd)
 	assert.NoError(t, err)
 	assert.True(t, sp.PreventsPushingSecrets())
@@ -69,6 +71,7 @@ func TestBuildSecurityPosture_SecretScanningDisabledButInsightsTooling(t *testin
 			},
 		},
 	}
+	rd.memos().secretsPolicyDocs.set(nil, nil)
 	sp, err := buildSecurityPosture(repo, rd)
 	assert.NoError(t, err)
 	assert.True(t, sp.PreventsPushingSecrets())
@@ -96,3 +99,108 @@ func TestInsightsClaimsSecretsTooling(t *testing.T) {
 	insights.Repository.SecurityPosture.Tools = nil
 	assert.False(t, insightsClaimsSecretsTooling(insights))
 }
+
+func TestFindSecretsPolicy(t *testing.T) {
+	tests := []struct {
+		name      string
+		documents []MarkdownDocument
+		insights  si.SecurityInsights
+		expected  SecretsPolicy
+	}{
+		{
+			name:     "no documents or insights",
+			expected: SecretsPolicy{},
+		},
+		{
+			name: "section covering all topics",
+			documents: []MarkdownDocument{
+				{
+					Path: "SECURITY.md",
+					Sections: []MarkdownSection{
+						{Heading: "Reporting a Vulnerability", Body: "Email the maintainers.\n"},
+						{Heading: "Handling Secrets", Body: "Secrets are stored in the CI vault. Access is restricted to maintainers. Tokens are rotated every 90 days.\n"},
+					},
+				},
+			},
+			expected: SecretsPolicy{Source: SecretsPolicySourceDocument, Location: "SECURITY.md", Topics: []string{SecretsTopicStoring, SecretsTopicAccessing, SecretsTopicRotating}},
+		},
+		{
+			name: "topics spread across sections are reported in order",
+			documents: []MarkdownDocument{
+				{Path: "SECURITY.md", Sections: []MarkdownSection{{Heading: "Reporting", Body: "Secrets are rotated often.\n"}}},
+				{
+					Path: "CONTRIBUTING.md",
+					Sections: []MarkdownSection{
+						{Heading: "Credential rotation", Body: "Revoke leaked credentials immediately.\n"},
+						{Heading: "Secret storage", Body: "Use the secrets manager.\n"},
+					},
+				},
+			},
+			expected: SecretsPolicy{Source: SecretsPolicySourceDocument, Location: "CONTRIBUTING.md", Topics: []string{SecretsTopicStoring, SecretsTopicRotating}},
+		},
+		{
+			name: "secrets heading without topics",
+			documents: []MarkdownDocument{
+				{Path: "SECURITY.md", Sections: []MarkdownSection{{Heading: "Secrets", Body: "Do not commit them.\n"}}},
+			},
+			expected: SecretsPolicy{Source: SecretsPolicySourceDocument, Location: "SECURITY.md"},
+		},
+		{
+			name: "insights link naming secrets",
+			insights: si.SecurityInsights{
+				Project: &si.Project{
+					Documentation: &si.ProjectDocumentation{
+						DetailedGuide: github.Ptr(si.URL("https://example.com/docs/secrets-management")),
+					},
+				},
+			},
+			expected: SecretsPolicy{Source: SecretsPolicySourceInsights, Location: "https://example.com/docs/secrets-management"},
+		},
+		{
+			name: "insights links unrelated to secrets",
+			insights: si.SecurityInsights{
+				Repository: &si.Repository{
+					Documentation: &si.RepositoryDocumentation{
+						SecurityPolicy: github.Ptr(si.URL("https://example.com/security")),
+					},
+				},
+			},
+			expected: SecretsPolicy{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, findSecretsPolicy(tt.documents, tt.insights))
+		})
+	}
+}
+
+func TestBuildSecurityPosture_SecretsPolicy(t *testing.T) {
+	rd := RestData{}
+	rd.memos().secretsPolicyDocs.set([]MarkdownDocument{
+		{Path: "SECURITY.md", Sections: []MarkdownSection{{Heading: "Secrets", Body: "Stored in a vault.\n"}}},
+	}, nil)
+	sp, err := buildSecurityPosture(&github.Repository{}, rd)
+	assert.NoError(t, err)
+	assert.True(t, sp.DefinesPolicyForHandlingSecrets())
+	assert.Equal(t, SecretsPolicy{Source: SecretsPolicySourceDocument, Location: "SECURITY.md", Topics: []string{SecretsTopicStoring}}, sp.SecretsPolicy())
+}
+
+func TestPayloadSecurityPosture(t *testing.T) {
+	_, err := (&Payload{}).SecurityPosture()
+	assert.EqualError(t, err, "repository data is not available in the payload")
+
+	rest := &RestData{}
+	rest.memos().secretsPolicyDocs.set([]MarkdownDocument{
+		{Path: "SECURITY.md", Sections: []MarkdownSection{{Heading: "Secrets", Body: "Stored in a vault.\n"}}},
+	}, nil)
+	payload := Payload{RestData: rest, repository: &github.Repository{}}
+	posture, err := payload.SecurityPosture()
+	assert.NoError(t, err)
+	assert.True(t, posture.DefinesPolicyForHandlingSecrets())
+
+	again, err := payload.SecurityPosture()
+	assert.NoError(t, err)
+	assert.Same(t, posture, again, "the posture is built once and shared by every step")
+}
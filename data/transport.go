@@ -0,0 +1,161 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	// maxRetries is how many times a rate limited or failed request is retried before its response is returned
+	maxRetries = 4
+	// maxRetryWait caps a single wait, which GitHub asks for in its headers, at the length of a rate limit window
+	maxRetryWait = time.Hour
+	// secondaryRateLimitWait is the minimum wait GitHub documents for a secondary rate limit that gives no Retry-After
+	secondaryRateLimitWait = time.Minute
+)
+
+// retryTransport is the http.RoundTripper under every client the Loader builds. It waits out primary and
+// secondary rate limits for as long as the Retry-After or X-RateLimit-Reset headers ask, backs off
+// exponentially on server errors, and logs the remaining quota at trace level.
+type retryTransport struct {
+	base   http.RoundTripper
+	logger hclog.Logger
+	now    func() time.Time
+	sleep  func(ctx context.Context, wait time.Duration) error
+}
+
+func newRetryTransport(logger hclog.Logger) *retryTransport {
+	if logger == nil {
+		logger = hclog.NewNullLogger()
+	}
+	return &retryTransport{
+		base:   http.DefaultTransport,
+		logger: logger,
+		now:    time.Now,
+		sleep:  sleepContext,
+	}
+}
+
+func (t *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		attemptRequest, err := rewindRequest(request, attempt)
+		if err != nil {
+			return nil, err
+		}
+		response, err := t.base.RoundTrip(attemptRequest)
+		if err != nil {
+			return nil, err
+		}
+		t.logRateLimit(response)
+
+		wait, retry := t.retryDelay(response, attempt)
+		rewindable := request.Body == nil || request.GetBody != nil
+		if !retry || !rewindable || attempt == maxRetries || wait > maxRetryWait {
+			return response, nil
+		}
+		t.logger.Debug(fmt.Sprintf("%s %s returned %s, retrying in %s", request.Method, request.URL.Redacted(), response.Status, wait))
+		_, _ = io.Copy(io.Discard, response.Body)
+		_ = response.Body.Close()
+
+		if err := t.sleep(request.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rewindRequest returns the request to send for an attempt, with a fresh copy of the body after the first
+func rewindRequest(request *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || request.Body == nil || request.GetBody == nil {
+		return request, nil
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	rewound := request.Clone(request.Context())
+	rewound.Body = body
+	return rewound, nil
+}
+
+// retryDelay reports whether a response should be retried and how long to wait first
+func (t *retryTransport) retryDelay(response *http.Response, attempt int) (wait time.Duration, retry bool) {
+	backoff := time.Second * time.Duration(math.Pow(2, float64(attempt)))
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests,
+		response.StatusCode == http.StatusForbidden && isRateLimited(response):
+		if wait, ok := t.headerDelay(response); ok {
+			return wait, true
+		}
+		return max(secondaryRateLimitWait, backoff), true
+	case response.StatusCode >= http.StatusInternalServerError && response.StatusCode != http.StatusNotImplemented:
+		if wait, ok := t.headerDelay(response); ok {
+			return wait, true
+		}
+		return backoff, true
+	}
+	return 0, false
+}
+
+// headerDelay returns the wait requested by the Retry-After header, or the time until the rate limit
+// resets when none of the quota remains
+func (t *retryTransport) headerDelay(response *http.Response) (time.Duration, bool) {
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if response.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			// a second past the reset, since the header is truncated to whole seconds
+			return max(time.Unix(reset, 0).Sub(t.now())+time.Second, 0), true
+		}
+	}
+	return 0, false
+}
+
+// isRateLimited distinguishes the 403 GitHub returns for a rate limit from one for missing permissions.
+// Secondary rate limits are only identified by the message in the body, which is restored for the caller.
+func isRateLimited(response *http.Response) bool {
+	if response.Header.Get("Retry-After") != "" || response.Header.Get("X-RateLimit-Remaining") == "0" {
+		return true
+	}
+	body, err := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	message := strings.ToLower(string(body))
+	return strings.Contains(message, "secondary rate limit") || strings.Contains(message, "abuse detection")
+}
+
+func (t *retryTransport) logRateLimit(response *http.Response) {
+	remaining := response.Header.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		return
+	}
+	resetAt := response.Header.Get("X-RateLimit-Reset")
+	if reset, err := strconv.ParseInt(resetAt, 10, 64); err == nil {
+		resetAt = time.Unix(reset, 0).Format(time.RFC3339)
+	}
+	t.logger.Trace(fmt.Sprintf("GitHub %s rate limit: %s of %s remaining, resets at %s",
+		response.Header.Get("X-RateLimit-Resource"), remaining, response.Header.Get("X-RateLimit-Limit"), resetAt))
+}
+
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,153 @@
+package data
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testResponse struct {
+	status  int
+	headers map[string]string
+	body    string
+}
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	reset := strconv.FormatInt(now.Add(30*time.Second).Unix(), 10)
+	ok := testResponse{status: http.StatusOK, body: "ok"}
+	serverError := testResponse{status: http.StatusBadGateway}
+
+	tests := []struct {
+		name           string
+		responses      []testResponse
+		expectedStatus int
+		expectedWaits  []time.Duration
+	}{
+		{
+			name:           "success is returned immediately",
+			responses:      []testResponse{ok},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "too many requests honors Retry-After",
+			responses: []testResponse{
+				{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "7"}},
+				ok,
+			},
+			expectedStatus: http.StatusOK,
+			expectedWaits:  []time.Duration{7 * time.Second},
+		},
+		{
+			name: "exhausted primary rate limit waits for the reset",
+			responses: []testResponse{
+				{status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}},
+				ok,
+			},
+			expectedStatus: http.StatusOK,
+			expectedWaits:  []time.Duration{31 * time.Second},
+		},
+		{
+			name: "secondary rate limit without headers waits a minute",
+			responses: []testResponse{
+				{status: http.StatusForbidden, body: `{"message": "You have exceeded a secondary rate limit."}`},
+				ok,
+			},
+			expectedStatus: http.StatusOK,
+			expectedWaits:  []time.Duration{time.Minute},
+		},
+		{
+			name:           "forbidden for missing permissions is not retried",
+			responses:      []testResponse{{status: http.StatusForbidden, body: `{"message": "Resource not accessible by integration"}`}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "server errors back off exponentially",
+			responses:      []testResponse{serverError, {status: http.StatusServiceUnavailable}, ok},
+			expectedStatus: http.StatusOK,
+			expectedWaits:  []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:           "persistent server errors are returned after the last retry",
+			responses:      []testResponse{serverError, serverError, serverError, serverError, serverError, ok},
+			expectedStatus: http.StatusBadGateway,
+			expectedWaits:  []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+		},
+		{
+			name:           "wait longer than a rate limit window is not attempted",
+			responses:      []testResponse{{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "7200"}}},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				response := tt.responses[requests]
+				requests++
+				for key, value := range response.headers {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(response.status)
+				_, _ = w.Write([]byte(response.body))
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			transport := newRetryTransport(hclog.NewNullLogger())
+			transport.now = func() time.Time { return now }
+			transport.sleep = func(ctx context.Context, wait time.Duration) error {
+				waits = append(waits, wait)
+				return nil
+			}
+
+			request, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"query": "{}"}`))
+			require.NoError(t, err)
+			response, err := (&http.Client{Transport: transport}).Do(request)
+			require.NoError(t, err)
+			defer func() { _ = response.Body.Close() }()
+
+			assert.Equal(t, tt.expectedStatus, response.StatusCode)
+			assert.Equal(t, tt.expectedWaits, waits)
+			for _, body := range bodies {
+				assert.Equal(t, `{"query": "{}"}`, body, "every attempt resends the request body")
+			}
+			if tt.expectedStatus == http.StatusForbidden {
+				body, _ := io.ReadAll(response.Body)
+				assert.Contains(t, string(body), "Resource not accessible", "the body read to check for a rate limit is restored")
+			}
+		})
+	}
+}
+
+func TestRetryTransportCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the scan is cancelled while the rate limited request waits to be retried
+		defer cancel()
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = newRetryTransport(hclog.NewNullLogger()).RoundTrip(request)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
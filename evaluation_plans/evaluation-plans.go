@@ -18,35 +18,36 @@ var (
 	// Open Source Project Security Baseline
 	OSPS = map[string][]gemara.AssessmentStep{
 		"OSPS-AC-01.01": {
-			access_control.OrgRequiresMFA,
+			reusable_steps.RequiresGitHubAPI(access_control.OrgRequiresMFA),
 		},
 		"OSPS-AC-02.01": {
 			reusable_steps.GithubBuiltIn,
 		},
 		"OSPS-AC-03.01": {
-			access_control.BranchProtectionRestrictsPushes,
+			reusable_steps.RequiresGitHubAPI(access_control.BranchProtectionRestrictsPushes),
 		},
 		"OSPS-AC-03.02": {
-			access_control.BranchProtectionPreventsDeletion,
+			reusable_steps.RequiresGitHubAPI(access_control.BranchProtectionPreventsDeletion),
 		},
 		"OSPS-AC-04.01": {
-			access_control.WorkflowDefaultReadPermissions,
+			reusable_steps.RequiresGitHubAPI(access_control.WorkflowDefaultReadPermissions),
 		},
 		"OSPS-AC-04.02": {
-			reusable_steps.NotImplemented,
+			access_control.WorkflowJobsUseLeastPrivilege,
 		},
 		"OSPS-BR-01.01": {
 			build_release.CicdSanitizedInputParameters,
 		},
 		"OSPS-BR-01.02": {
-			reusable_steps.NotImplemented,
+			build_release.CicdSanitizedBranchNames,
 		},
 		"OSPS-BR-02.01": {
 			reusable_steps.HasMadeReleases,
 			build_release.ReleaseHasUniqueIdentifier,
 		},
 		"OSPS-BR-02.02": {
-			reusable_steps.NotImplemented,
+			reusable_steps.HasMadeReleases,
+			build_release.ReleaseAssetsNamedForRelease,
 		},
 		"OSPS-BR-03.01": {
 			reusable_steps.HasSecurityInsightsFile,
@@ -57,10 +58,11 @@ var (
 		},
 		"OSPS-BR-04.01": {
 			reusable_steps.HasMadeReleases,
-			build_release.EnsureLatestReleaseHasChangelog,
+			reusable_steps.RequiresGitHubAPI(build_release.EnsureLatestReleaseHasChangelog),
 		},
 		"OSPS-BR-05.01": {
-			reusable_steps.NotImplemented,
+			reusable_steps.IsCodeRepo,
+			reusable_steps.RequiresGitHubAPI(build_release.StandardizedDependencyIngestion),
 		},
 		"OSPS-BR-06.01": {
 			reusable_steps.HasMadeReleases,
@@ -68,10 +70,10 @@ var (
 			build_release.InsightsHasSlsaAttestation,
 		},
 		"OSPS-BR-07.01": {
-			build_release.SecretScanningInUse,
+			reusable_steps.RequiresGitHubAPI(build_release.SecretScanningInUse),
 		},
 		"OSPS-BR-07.02": {
-			reusable_steps.NotImplemented,
+			build_release.SecretsPolicyDefined,
 		},
 		"OSPS-DO-01.01": {
 			reusable_steps.HasMadeReleases,
@@ -80,7 +82,7 @@ var (
 		},
 		"OSPS-DO-02.01": {
 			reusable_steps.HasMadeReleases,
-			reusable_steps.HasIssuesOrDiscussionsEnabled,
+			reusable_steps.RequiresGitHubAPI(reusable_steps.HasIssuesOrDiscussionsEnabled),
 			docs.AcceptsVulnReports,
 		},
 		"OSPS-DO-03.01": {
@@ -97,7 +99,7 @@ var (
 			docs.HasSupportDocs,
 		},
 		"OSPS-DO-05.01": {
-			reusable_steps.NotImplemented,
+			docs.HasEndOfSupportStatement,
 		},
 		"OSPS-DO-06.01": {
 			reusable_steps.IsCodeRepo,
@@ -115,7 +117,7 @@ var (
 			governance.HasRolesAndResponsibilities,
 		},
 		"OSPS-GV-02.01": {
-			reusable_steps.HasIssuesOrDiscussionsEnabled,
+			reusable_steps.RequiresGitHubAPI(reusable_steps.HasIssuesOrDiscussionsEnabled),
 		},
 		"OSPS-GV-03.01": {
 			governance.HasContributionGuide,
@@ -127,7 +129,7 @@ var (
 			governance.HasContributionReviewPolicy,
 		},
 		"OSPS-GV-04.01": {
-			reusable_steps.NotImplemented,
+			governance.HasCollaboratorReviewPolicy,
 		},
 		"OSPS-LE-01.01": {
 			reusable_steps.GithubTermsOfService,
@@ -147,20 +149,21 @@ var (
 			legal.ReleasesLicensed,
 		},
 		"OSPS-QA-01.01": {
-			quality.RepoIsPublic,
+			reusable_steps.RequiresGitHubAPI(quality.RepoIsPublic),
 		},
 		"OSPS-QA-01.02": {
 			reusable_steps.GithubBuiltIn,
 		},
 		"OSPS-QA-02.01": {
-			quality.VerifyDependencyManagement,
+			reusable_steps.RequiresGitHubAPI(quality.VerifyDependencyManagement),
 		},
 		"OSPS-QA-02.02": {
-			reusable_steps.NotImplemented,
+			reusable_steps.HasMadeReleases,
+			reusable_steps.RequiresGitHubAPI(quality.ReleaseHasSbom),
 		},
 		"OSPS-QA-03.01": {
-			quality.StatusChecksAreRequiredByRulesets,
-			quality.StatusChecksAreRequiredByBranchProtection,
+			reusable_steps.RequiresGitHubAPI(quality.StatusChecksAreRequiredByRulesets),
+			reusable_steps.RequiresGitHubAPI(quality.StatusChecksAreRequiredByBranchProtection),
 		},
 		"OSPS-QA-04.01": {
 			reusable_steps.IsCodeRepo,
@@ -169,17 +172,20 @@ var (
 			quality.InsightsListsRepositories,
 		},
 		"OSPS-QA-04.02": {
-			reusable_steps.NotImplemented,
+			reusable_steps.IsCodeRepo,
+			reusable_steps.HasSecurityInsightsFile,
+			quality.InsightsListsRepositories,
+			reusable_steps.RequiresGitHubAPI(quality.SubprojectsMatchPrimarySecurity),
 		},
 		"OSPS-QA-05.01": {
 			quality.NoBinariesInRepo,
 		},
 		"OSPS-QA-05.02": {
-			reusable_steps.NotImplemented,
+			quality.NoUnreviewableBinariesInRepo,
 		},
 		"OSPS-QA-06.01": {
 			reusable_steps.IsCodeRepo,
-			quality.HasOneOrMoreStatusChecks,
+			reusable_steps.RequiresGitHubAPI(quality.HasOneOrMoreStatusChecks),
 		},
 		"OSPS-QA-06.02": {
 			quality.DocumentsTestExecution,
@@ -189,20 +195,20 @@ var (
 			quality.DocumentsTestMaintenancePolicy,
 		},
 		"OSPS-QA-07.01": {
-			quality.RequiresNonAuthorApproval,
+			reusable_steps.RequiresGitHubAPI(quality.RequiresNonAuthorApproval),
 		},
 		"OSPS-SA-01.01": {
 			reusable_steps.HasMadeReleases,
 			sec_assessment.HasDesignDocumentation,
 		},
 		"OSPS-SA-02.01": {
-			reusable_steps.NotImplemented,
+			sec_assessment.HasExternalInterfaceDocumentation,
 		},
 		"OSPS-SA-03.01": {
-			reusable_steps.NotImplemented,
+			sec_assessment.HasSecurityAssessment,
 		},
 		"OSPS-SA-03.02": {
-			reusable_steps.NotImplemented,
+			sec_assessment.HasThreatModel,
 		},
 		"OSPS-VM-01.01": {
 			reusable_steps.IsActive,
@@ -219,19 +225,20 @@ var (
 			vuln_management.HasPrivateVulnerabilityReporting,
 		},
 		"OSPS-VM-04.01": {
-			reusable_steps.NotImplemented,
+			reusable_steps.RequiresGitHubAPI(vuln_management.PublishesVulnerabilityData),
 		},
 		"OSPS-VM-04.02": {
-			reusable_steps.NotImplemented,
+			vuln_management.HasVexDocuments,
 		},
 		"OSPS-VM-05.01": {
-			reusable_steps.NotImplemented,
+			vuln_management.HasScaRemediationThreshold,
 		},
 		"OSPS-VM-05.03": {
-			reusable_steps.NotImplemented,
+			reusable_steps.IsCodeRepo,
+			reusable_steps.RequiresGitHubAPI(vuln_management.DependencyScanningIsRequired),
 		},
 		"OSPS-VM-05.02": {
-			reusable_steps.NotImplemented,
+			vuln_management.HasScaPreReleasePolicy,
 		},
 		"OSPS-VM-06.01": {
 			reusable_steps.HasDependencyManagementPolicy,
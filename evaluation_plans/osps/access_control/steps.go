@@ -1,7 +1,13 @@
 package access_control
 
 import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
 	"github.com/gemaraproj/go-gemara"
+	"github.com/rhysd/actionlint"
 
 	"github.com/ossf/pvtr-github-repo-scanner/evaluation_plans/reusable_steps"
 )
@@ -80,8 +86,11 @@ func WorkflowDefaultReadPermissions(payloadData any) (result gemara.Result, mess
 		return gemara.Unknown, message, confidence
 	}
 
-	permissions := payload.WorkflowPermissions
-	if !payload.WorkflowsEnabled {
+	permissions, err := payload.WorkflowPermissions()
+	if err != nil {
+		return gemara.Unknown, fmt.Sprintf("Failed to retrieve workflow permissions: %s", err.Error()), confidence
+	}
+	if !permissions.Enabled {
 		return gemara.NeedsReview, "GitHub Actions is disabled for this repository; manual review required.", confidence
 	}
 
@@ -100,3 +109,207 @@ func WorkflowDefaultReadPermissions(payloadData any) (result gemara.Result, mess
 	}
 	return
 }
+
+// writeScopeIndicator lists the actions and shell commands that commonly require write access to a GITHUB_TOKEN scope
+type writeScopeIndicator struct {
+	actions  []string
+	commands []string
+}
+
+// writeScopeIndicators is used to decide whether a job plausibly needs the write scopes it requests
+var writeScopeIndicators = map[string]writeScopeIndicator{
+	"actions": {
+		commands: []string{"gh workflow", "gh run", "gh cache"},
+	},
+	"attestations": {
+		actions: []string{"actions/attest"},
+	},
+	"checks": {
+		actions: []string{"dorny/test-reporter", "mikepenz/action-junit-report", "enricomi/publish-unit-test-result-action"},
+	},
+	"contents": {
+		actions: []string{
+			"softprops/action-gh-release",
+			"ncipollo/release-action",
+			"actions/create-release",
+			"goreleaser/goreleaser-action",
+			"release-drafter/release-drafter",
+			"googleapis/release-please-action",
+			"stefanzweifel/git-auto-commit-action",
+			"peter-evans/create-pull-request",
+			"ad-m/github-push-action",
+			"peaceiris/actions-gh-pages",
+		},
+		commands: []string{"git push", "gh release", "goreleaser"},
+	},
+	"deployments": {
+		actions: []string{"actions/deploy-pages", "chrnorm/deployment-action", "bobheadxi/deployments"},
+	},
+	"discussions": {
+		// discussions can only be written through these GraphQL mutations, so a query alone does not need the scope
+		commands: []string{"createDiscussion", "updateDiscussion", "closeDiscussion", "addDiscussionComment", "markDiscussionCommentAsAnswer"},
+	},
+	"id-token": {
+		actions: []string{
+			"actions/attest",
+			"sigstore/",
+			"slsa-framework/",
+			"aws-actions/configure-aws-credentials",
+			"azure/login",
+			"google-github-actions/auth",
+			"pypa/gh-action-pypi-publish",
+			"actions/deploy-pages",
+		},
+		commands: []string{"cosign", "ACTIONS_ID_TOKEN_REQUEST"},
+	},
+	"issues": {
+		actions:  []string{"actions/stale", "actions/labeler", "peter-evans/create-or-update-comment", "peter-evans/create-issue-from-file"},
+		commands: []string{"gh issue", "gh label"},
+	},
+	"packages": {
+		actions:  []string{"docker/login-action", "docker/build-push-action", "goreleaser/goreleaser-action"},
+		commands: []string{"docker push", "npm publish", "mvn deploy", "gradle publish", "dotnet nuget push", "ghcr.io"},
+	},
+	"pages": {
+		actions: []string{"actions/deploy-pages"},
+	},
+	"pull-requests": {
+		actions: []string{
+			"peter-evans/create-pull-request",
+			"peter-evans/create-or-update-comment",
+			"marocchino/sticky-pull-request-comment",
+			"actions/labeler",
+			"actions/stale",
+			"release-drafter/release-drafter",
+			"googleapis/release-please-action",
+			"dependabot/fetch-metadata",
+		},
+		commands: []string{"gh pr"},
+	},
+	"security-events": {
+		actions:  []string{"github/codeql-action", "ossf/scorecard-action", "aquasecurity/trivy-action", "anchore/scan-action"},
+		commands: []string{"codeql", "upload-sarif"},
+	},
+	"statuses": {
+		actions:  []string{"myrotvorets/set-commit-status-action", "ouzi-dev/commit-status-updater"},
+		commands: []string{"/statuses/"},
+	},
+}
+
+// WorkflowJobsUseLeastPrivilege checks that every job declares the GITHUB_TOKEN permissions it needs and no more.
+// A job may declare them in its own permissions block or inherit the workflow-level block; only jobs with
+// neither fall back to the repository default and are flagged.
+func WorkflowJobsUseLeastPrivilege(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	payload, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	workflows, result, message := reusable_steps.ParseWorkflows(payload)
+	if message != "" {
+		return result, message, confidence
+	}
+
+	var violations, unneededScopes []string
+	for _, workflow := range workflows {
+		workflowViolations, workflowUnneededScopes := checkJobPermissions(workflow.Workflow)
+		for _, violation := range workflowViolations {
+			violations = append(violations, fmt.Sprintf("%s: %s", workflow.Path, violation))
+		}
+		for _, scope := range workflowUnneededScopes {
+			unneededScopes = append(unneededScopes, fmt.Sprintf("%s: %s", workflow.Path, scope))
+		}
+	}
+
+	if len(violations) > 0 {
+		return gemara.Failed, fmt.Sprintf("Workflow jobs do not follow least privilege: %s", strings.Join(append(violations, unneededScopes...), "; ")), confidence
+	}
+	if len(unneededScopes) > 0 {
+		return gemara.NeedsReview, fmt.Sprintf("Workflow jobs request write permissions that no step appears to need: %s", strings.Join(unneededScopes, "; ")), confidence
+	}
+	return gemara.Passed, "All workflow jobs declare least-privilege permissions in their own or the workflow-level permissions block", confidence
+}
+
+// checkJobPermissions returns jobs without explicit permissions or with write-all permissions as violations,
+// and write scopes that no step in the job appears to need as unneeded scopes. Permissions inherited from
+// the workflow-level block count as explicit, and are checked against each job that inherits them.
+func checkJobPermissions(workflow *actionlint.Workflow) (violations []string, unneededScopes []string) {
+	for _, id := range slices.Sorted(maps.Keys(workflow.Jobs)) {
+		job := workflow.Jobs[id]
+		if job == nil {
+			continue
+		}
+		if job.ID != nil {
+			id = job.ID.Value
+		}
+
+		// jobs without their own permissions block inherit the workflow-level permissions
+		permissions := job.Permissions
+		if permissions == nil {
+			permissions = workflow.Permissions
+		}
+		if permissions == nil {
+			violations = append(violations, fmt.Sprintf("job '%s' has no explicit permissions block", id))
+			continue
+		}
+		if permissions.All != nil && permissions.All.Value == "write-all" {
+			violations = append(violations, fmt.Sprintf("job '%s' uses write-all permissions", id))
+			continue
+		}
+
+		// permissions granted to a reusable workflow call are used by the called workflow, which is not visible here
+		if job.WorkflowCall != nil {
+			continue
+		}
+
+		for _, scope := range slices.Sorted(maps.Keys(permissions.Scopes)) {
+			permission := permissions.Scopes[scope]
+			if permission == nil || permission.Value == nil || permission.Value.Value != "write" {
+				continue
+			}
+			if !jobNeedsWriteScope(job, scope) {
+				unneededScopes = append(unneededScopes, fmt.Sprintf("job '%s' requests %s: write", id, scope))
+			}
+		}
+	}
+	return violations, unneededScopes
+}
+
+// jobNeedsWriteScope returns true when any step in the job uses an action or command known to require write access to the scope
+func jobNeedsWriteScope(job *actionlint.Job, scope string) bool {
+	indicator, known := writeScopeIndicators[scope]
+	if !known {
+		return false
+	}
+	for _, step := range job.Steps {
+		if step == nil {
+			continue
+		}
+		switch exec := step.Exec.(type) {
+		case *actionlint.ExecAction:
+			if exec.Uses == nil {
+				continue
+			}
+			uses := strings.ToLower(exec.Uses.Value)
+			// github-script can call any API with the job token, so it may need any scope
+			if strings.HasPrefix(uses, "actions/github-script") {
+				return true
+			}
+			for _, action := range indicator.actions {
+				if strings.HasPrefix(uses, action) {
+					return true
+				}
+			}
+		case *actionlint.ExecRun:
+			if exec.Run == nil {
+				continue
+			}
+			for _, command := range indicator.commands {
+				if strings.Contains(exec.Run.Value, command) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
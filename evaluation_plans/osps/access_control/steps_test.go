@@ -5,6 +5,7 @@ import (
 
 	"github.com/gemaraproj/go-gemara"
 	"github.com/ossf/pvtr-github-repo-scanner/data"
+	"github.com/rhysd/actionlint"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -91,76 +92,56 @@ func Test_OrgRequiresMFA(t *testing.T) {
 func Test_WorkflowDefaultReadPermissions(t *testing.T) {
 	tests := []struct {
 		name        string
-		payload     data.Payload
+		permissions data.WorkflowPermissions
 		wantResult  gemara.Result
 		wantMessage string
 	}{
 		{
 			name: "Workflows enabled, read permissions and no PR permissions",
-			payload: data.Payload{
-				RestData: &data.RestData{
-					WorkflowsEnabled: true,
-					WorkflowPermissions: data.WorkflowPermissions{
-						DefaultPermissions:    "read", // read access for the contents and packages permissions
-						CanApprovePullRequest: false,  // cannot create or approve PRs
-					},
-				},
+			permissions: data.WorkflowPermissions{
+				Enabled:               true,
+				DefaultPermissions:    "read", // read access for the contents and packages permissions
+				CanApprovePullRequest: false,  // cannot create or approve PRs
 			},
 			wantResult:  gemara.Passed,
 			wantMessage: "Workflow permissions default to read only.",
 		},
 		{
 			name: "Workflows enabled, read permissions, but allows PR approvals",
-			payload: data.Payload{
-				RestData: &data.RestData{
-					WorkflowsEnabled: true,
-					WorkflowPermissions: data.WorkflowPermissions{
-						DefaultPermissions:    "read", // read access for the contents and packages permissions
-						CanApprovePullRequest: true,   // can create & approve PRs
-					},
-				},
+			permissions: data.WorkflowPermissions{
+				Enabled:               true,
+				DefaultPermissions:    "read", // read access for the contents and packages permissions
+				CanApprovePullRequest: true,   // can create & approve PRs
 			},
 			wantResult:  gemara.Failed,
 			wantMessage: "Workflow permissions default to read only for contents and packages, but PR approval is permitted.",
 		},
 		{
 			name: "Workflows enabled, write permissions and no PR permissions",
-			payload: data.Payload{
-				RestData: &data.RestData{
-					WorkflowsEnabled: true,
-					WorkflowPermissions: data.WorkflowPermissions{
-						DefaultPermissions:    "write", // read & write access for all permission scopes
-						CanApprovePullRequest: false,   // cannot create or approve PRs (in theory at least)
-					},
-				},
+			permissions: data.WorkflowPermissions{
+				Enabled:               true,
+				DefaultPermissions:    "write", // read & write access for all permission scopes
+				CanApprovePullRequest: false,   // cannot create or approve PRs (in theory at least)
 			},
 			wantResult:  gemara.Failed,
 			wantMessage: "Workflow permissions default to read/write, but PR approval is forbidden.",
 		},
 		{
 			name: "Workflows enabled, write permissions and PR permissions",
-			payload: data.Payload{
-				RestData: &data.RestData{
-					WorkflowsEnabled: true,
-					WorkflowPermissions: data.WorkflowPermissions{
-						DefaultPermissions:    "write",
-						CanApprovePullRequest: true,
-					},
-				},
+			permissions: data.WorkflowPermissions{
+				Enabled:               true,
+				DefaultPermissions:    "write",
+				CanApprovePullRequest: true,
 			},
 			wantResult:  gemara.Failed,
 			wantMessage: "Workflow permissions default to read/write and PR approval is permitted.",
 		},
 		{
 			name: "Workflows disabled",
-			payload: data.Payload{
-				RestData: &data.RestData{
-					WorkflowsEnabled: false,
-					WorkflowPermissions: data.WorkflowPermissions{
-						DefaultPermissions:    "write",
-						CanApprovePullRequest: true,
-					},
-				},
+			permissions: data.WorkflowPermissions{
+				Enabled:               false,
+				DefaultPermissions:    "write",
+				CanApprovePullRequest: true,
 			},
 			wantResult:  gemara.NeedsReview,
 			wantMessage: "GitHub Actions is disabled for this repository; manual review required.",
@@ -169,7 +150,8 @@ func Test_WorkflowDefaultReadPermissions(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotResult, gotMessage, _ := WorkflowDefaultReadPermissions(tt.payload)
+			payload := data.NewPayloadWithWorkflowPermissions(data.Payload{RestData: &data.RestData{}}, tt.permissions)
+			gotResult, gotMessage, _ := WorkflowDefaultReadPermissions(payload)
 			assert.Equal(t, tt.wantResult, gotResult)
 			assert.Equal(t, tt.wantMessage, gotMessage)
 		})
@@ -340,3 +322,153 @@ func Test_BranchProtectionPreventsDeletion(t *testing.T) {
 		})
 	}
 }
+
+func Test_checkJobPermissions(t *testing.T) {
+	tests := []struct {
+		name               string
+		workflowFile       string
+		wantViolations     []string
+		wantUnneededScopes []string
+	}{
+		{
+			name: "job with read-only permissions",
+			workflowFile: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    steps:
+      - uses: actions/checkout@v5
+      - run: go test ./...`,
+		},
+		{
+			name: "job inherits workflow permissions",
+			workflowFile: `on: push
+permissions:
+  contents: read
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: go test ./...`,
+		},
+		{
+			name: "job without permissions block",
+			workflowFile: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: go test ./...`,
+			wantViolations: []string{"job 'test' has no explicit permissions block"},
+		},
+		{
+			name: "job with write-all permissions",
+			workflowFile: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    permissions: write-all
+    steps:
+      - run: make build`,
+			wantViolations: []string{"job 'build' uses write-all permissions"},
+		},
+		{
+			name: "write scope needed by a release action",
+			workflowFile: `on: push
+jobs:
+  release:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: write
+      id-token: write
+    steps:
+      - uses: goreleaser/goreleaser-action@v6
+      - uses: sigstore/cosign-installer@v3`,
+		},
+		{
+			name: "write scope needed by a shell command",
+			workflowFile: `on: push
+jobs:
+  publish:
+    runs-on: ubuntu-latest
+    permissions:
+      packages: write
+    steps:
+      - run: docker push ghcr.io/org/image:latest`,
+		},
+		{
+			name: "write scope not needed by any step",
+			workflowFile: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: write
+      pull-requests: write
+    steps:
+      - uses: actions/checkout@v5
+      - run: go test ./...`,
+			wantUnneededScopes: []string{
+				"job 'test' requests contents: write",
+				"job 'test' requests pull-requests: write",
+			},
+		},
+		{
+			name: "github-script may need any scope",
+			workflowFile: `on: push
+jobs:
+  triage:
+    runs-on: ubuntu-latest
+    permissions:
+      issues: write
+    steps:
+      - uses: actions/github-script@v7`,
+		},
+		{
+			name: "discussion mutation needs discussions write",
+			workflowFile: `on: push
+jobs:
+  announce:
+    runs-on: ubuntu-latest
+    permissions:
+      discussions: write
+    steps:
+      - run: |
+          gh api graphql -f query='mutation { createDiscussion(input: $input) { discussion { url } } }'`,
+		},
+		{
+			name: "graphql query does not need discussions write",
+			workflowFile: `on: push
+jobs:
+  report:
+    runs-on: ubuntu-latest
+    permissions:
+      discussions: write
+    steps:
+      - run: |
+          gh api graphql -f query='query { viewer { login } }'`,
+			wantUnneededScopes: []string{"job 'report' requests discussions: write"},
+		},
+		{
+			name: "reusable workflow call is not analyzed for scopes",
+			workflowFile: `on: push
+jobs:
+  call:
+    permissions:
+      contents: write
+    uses: org/repo/.github/workflows/release.yml@main`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow, errs := actionlint.Parse([]byte(tt.workflowFile))
+			assert.Empty(t, errs)
+			violations, unneededScopes := checkJobPermissions(workflow)
+			assert.Equal(t, tt.wantViolations, violations)
+			assert.Equal(t, tt.wantUnneededScopes, unneededScopes)
+		})
+	}
+}
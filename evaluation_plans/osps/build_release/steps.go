@@ -1,9 +1,11 @@
 package build_release
 
 import (
-	"encoding/base64"
 	"fmt"
+	"maps"
+	"path"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/gemaraproj/go-gemara"
@@ -41,37 +43,14 @@ func CicdSanitizedInputParameters(payloadData any) (result gemara.Result, messag
 	if message != "" {
 		return gemara.Unknown, message, confidence
 	}
-	workflows, err := data.GetDirectoryContent(".github/workflows")
-	if len(workflows) == 0 {
-		if err != nil {
-			message = err.Error()
-		} else {
-			message = "No workflows found in .github/workflows directory"
-		}
-		return gemara.NotApplicable, message, confidence
+	workflows, result, message := reusable_steps.ParseWorkflows(data)
+	if message != "" {
+		return result, message, confidence
 	}
 
-	for _, file := range workflows {
-		if !strings.HasSuffix(*file.Name, ".yml") && !strings.HasSuffix(*file.Name, ".yaml") {
-			continue
-		}
-
-		if *file.Encoding != "base64" {
-			return gemara.Failed, fmt.Sprintf("File %v is not base64 encoded", file.Name), confidence
-		}
-
-		decoded, err := base64.StdEncoding.DecodeString(*file.Content)
-		if err != nil {
-			return gemara.Failed, fmt.Sprintf("Error decoding workflow file: %v", err), confidence
-		}
-
-		workflow, actionError := actionlint.Parse(decoded)
-		if actionError != nil {
-			return gemara.Failed, fmt.Sprintf("Error parsing workflow: %v (%s)", actionError, *file.Path), confidence
-		}
-
+	for _, workflow := range workflows {
 		// Check the workflow for untrusted inputs
-		ok, message := checkWorkflowFileForUntrustedInputs(workflow)
+		ok, message := checkWorkflowFileForUntrustedInputs(workflow.Workflow)
 
 		if !ok {
 			return gemara.Failed, message, confidence
@@ -155,17 +134,293 @@ func pullVariablesFromScript(script string) []string {
 
 }
 
+// Branch names are chosen by whoever pushes the branch, so they must not reach scripts unsanitized
+// Global for use in tests also
+var branchVarsRegex = `(github\.head_ref|` +
+	`github\.ref_name|` +
+	`github\.event\.pull_request\.head\.ref|` +
+	`github\.event\.pull_request\.head\.label|` +
+	`github\.event\.workflow_run\.head_branch|` +
+	`github\.event\.check_suite\.head_branch|` +
+	`github\.event\.check_run\.check_suite\.head_branch|` +
+	`github\.event\.merge_group\.head_ref)\b`
+
+// scriptInputs are action inputs that are evaluated as code by the action
+var scriptInputs = []string{"script", "run", "command", "commands"}
+
+func CicdSanitizedBranchNames(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	data, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+	workflows, result, message := reusable_steps.ParseWorkflows(data)
+	if message != "" {
+		return result, message, confidence
+	}
+
+	var findings []string
+	for _, workflow := range workflows {
+		ok, message := checkWorkflowFileForUnsanitizedBranchNames(workflow.Workflow)
+		if !ok {
+			findings = append(findings, fmt.Sprintf("%s:\n%s", workflow.Path, message))
+		}
+	}
+	if len(findings) > 0 {
+		return gemara.Failed, strings.Join(findings, ""), confidence
+	}
+
+	return gemara.Passed, "GitHub Workflows do not pass branch names to scripts without sanitization", confidence
+}
+
+// branchTaint tracks which env vars and outputs carry a branch name through a workflow
+type branchTaint struct {
+	branchVars  *regexp.Regexp
+	env         map[string]bool
+	stepOutputs map[string]bool
+	jobOutputs  map[string]bool
+}
+
+func (t *branchTaint) expressionIsTainted(expression string) bool {
+	if t.branchVars.MatchString(expression) {
+		return true
+	}
+	for name := range t.env {
+		if referencesContext(expression, "env."+name) {
+			return true
+		}
+	}
+	for stepID := range t.stepOutputs {
+		if referencesContext(expression, "steps."+stepID+".outputs.") {
+			return true
+		}
+	}
+	for output := range t.jobOutputs {
+		if referencesContext(expression, output) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesContext reports whether expression contains reference as a whole context path,
+// so that env.FOO is not found in env.FOOBAR or myenv.FOO
+func referencesContext(expression, reference string) bool {
+	for offset := 0; ; {
+		index := strings.Index(expression[offset:], reference)
+		if index < 0 {
+			return false
+		}
+		start := offset + index
+		end := start + len(reference)
+		startsPath := start == 0 || !isContextPathChar(expression[start-1])
+		endsPath := strings.HasSuffix(reference, ".") || end == len(expression) || !isIdentifierChar(expression[end])
+		if startsPath && endsPath {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '-' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func isContextPathChar(c byte) bool {
+	return c == '.' || isIdentifierChar(c)
+}
+
+// taintedExpressions returns the ${{ }} expressions in value that carry a branch name
+func (t *branchTaint) taintedExpressions(value string) (tainted []string) {
+	for _, expression := range pullVariablesFromScript(value) {
+		if t.expressionIsTainted(expression) {
+			tainted = append(tainted, expression)
+		}
+	}
+	return tainted
+}
+
+// withEnv returns a copy of the taint state with the tainted variables of env added
+func (t *branchTaint) withEnv(env *actionlint.Env) *branchTaint {
+	scoped := &branchTaint{
+		branchVars:  t.branchVars,
+		env:         maps.Clone(t.env),
+		stepOutputs: t.stepOutputs,
+		jobOutputs:  t.jobOutputs,
+	}
+	if env == nil {
+		return scoped
+	}
+	for _, variable := range env.Vars {
+		if variable == nil || variable.Name == nil || variable.Value == nil {
+			continue
+		}
+		if len(t.taintedExpressions(variable.Value.Value)) > 0 {
+			scoped.env[variable.Name.Value] = true
+		}
+	}
+	return scoped
+}
+
+func checkWorkflowFileForUnsanitizedBranchNames(workflow *actionlint.Workflow) (bool, string) {
+	taint := &branchTaint{
+		branchVars: regexp.MustCompile(branchVarsRegex),
+		env:        map[string]bool{},
+		jobOutputs: map[string]bool{},
+	}
+	taint = taint.withEnv(workflow.Env)
+	jobIDs := slices.Sorted(maps.Keys(workflow.Jobs))
+
+	// job outputs can feed later jobs through needs, so propagate them until nothing changes
+	for range jobIDs {
+		changed := false
+		for _, id := range jobIDs {
+			_, outputs := checkJobForUnsanitizedBranchNames(id, workflow.Jobs[id], taint)
+			for _, output := range outputs {
+				if !taint.jobOutputs[output] {
+					taint.jobOutputs[output] = true
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	var message strings.Builder
+	for _, id := range jobIDs {
+		findings, _ := checkJobForUnsanitizedBranchNames(id, workflow.Jobs[id], taint)
+		for _, finding := range findings {
+			message.WriteString(fmt.Sprintf("Unsanitized branch name found: %s\n", finding))
+		}
+	}
+
+	if message.Len() > 0 {
+		return false, message.String()
+	}
+	return true, ""
+}
+
+// checkJobForUnsanitizedBranchNames returns the places where a branch name reaches a script in the job,
+// along with the job outputs that carry a branch name
+func checkJobForUnsanitizedBranchNames(id string, job *actionlint.Job, workflowTaint *branchTaint) (findings []string, outputs []string) {
+	if job == nil {
+		return nil, nil
+	}
+	jobTaint := workflowTaint.withEnv(job.Env)
+	jobTaint.stepOutputs = map[string]bool{}
+
+	for _, step := range job.Steps {
+		if step == nil {
+			continue
+		}
+		stepTaint := jobTaint.withEnv(step.Env)
+
+		switch exec := step.Exec.(type) {
+		case *actionlint.ExecRun:
+			if exec.Run == nil {
+				continue
+			}
+			script := exec.Run.Value
+			tainted := stepTaint.taintedExpressions(script)
+			for _, expression := range tainted {
+				findings = append(findings, fmt.Sprintf("%s is interpolated into a run script in job '%s'", expression, id))
+			}
+			var taintedVars []string
+			for _, name := range slices.Sorted(maps.Keys(stepTaint.env)) {
+				if referencesEnvVar(script, name) {
+					taintedVars = append(taintedVars, name)
+				}
+				if unquotedEnvVar(script, name) {
+					findings = append(findings, fmt.Sprintf("$%s is used without quotes in a run script in job '%s'", name, id))
+				}
+			}
+			if step.ID != nil && strings.Contains(script, "GITHUB_OUTPUT") && (len(tainted) > 0 || len(taintedVars) > 0) {
+				jobTaint.stepOutputs[step.ID.Value] = true
+			}
+		case *actionlint.ExecAction:
+			if exec.Uses == nil {
+				continue
+			}
+			for _, name := range slices.Sorted(maps.Keys(exec.Inputs)) {
+				input := exec.Inputs[name]
+				if !slices.Contains(scriptInputs, name) || input == nil || input.Value == nil {
+					continue
+				}
+				for _, expression := range stepTaint.taintedExpressions(input.Value.Value) {
+					findings = append(findings, fmt.Sprintf("%s is passed to the '%s' input of %s in job '%s'", expression, name, exec.Uses.Value, id))
+				}
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(job.Outputs)) {
+		output := job.Outputs[name]
+		if output == nil || output.Value == nil {
+			continue
+		}
+		if len(jobTaint.taintedExpressions(output.Value.Value)) > 0 {
+			outputs = append(outputs, fmt.Sprintf("needs.%s.outputs.%s", id, name))
+		}
+	}
+	return findings, outputs
+}
+
+func envVarRegex(name string) *regexp.Regexp {
+	return regexp.MustCompile(`\$(` + regexp.QuoteMeta(name) + `\b|\{` + regexp.QuoteMeta(name) + `\})`)
+}
+
+func referencesEnvVar(script string, name string) bool {
+	return envVarRegex(name).MatchString(script)
+}
+
+// unquotedEnvVar returns true when the env var is expanded in the script outside of single or double quotes
+func unquotedEnvVar(script string, name string) bool {
+	for _, line := range strings.Split(script, "\n") {
+		for _, match := range envVarRegex(name).FindAllStringIndex(line, -1) {
+			if !insideQuotes(line[:match[0]]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// insideQuotes returns true when a quoted string is still open at the end of prefix
+func insideQuotes(prefix string) bool {
+	var quote rune
+	escaped := false
+	for _, char := range prefix {
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\' && quote != '\'':
+			escaped = true
+		case quote == 0 && (char == '"' || char == '\''):
+			quote = char
+		case char == quote:
+			quote = 0
+		}
+	}
+	return quote != 0
+}
+
 func ReleaseHasUniqueIdentifier(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
 	data, message := reusable_steps.VerifyPayload(payloadData)
 	if message != "" {
 		return gemara.Unknown, message, confidence
 	}
 
+	releases, err := data.Releases()
+	if err != nil {
+		return gemara.Unknown, err.Error(), confidence
+	}
+
 	var noNameCount int
 	var sameNameFound []string
 	var releaseNames = make(map[string]int)
 
-	for _, release := range data.Releases {
+	for _, release := range releases {
 		if release.Name == "" {
 			noNameCount++
 		} else if _, ok := releaseNames[release.Name]; ok {
@@ -185,6 +440,127 @@ func ReleaseHasUniqueIdentifier(payloadData any) (result gemara.Result, message
 	return gemara.Passed, "All releases found have a unique name", confidence
 }
 
+var semverRegex = regexp.MustCompile(`\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?`)
+
+// releaseAssetNormalizations are the ways a release identifier may be rewritten in asset names.
+// They can be restricted with the comma separated release-asset-normalizations config var.
+var releaseAssetNormalizations = map[string]func(string) string{
+	"v-prefix": func(identifier string) string {
+		return strings.TrimPrefix(strings.TrimPrefix(identifier, "v"), "V")
+	},
+	"semver": func(identifier string) string {
+		return semverRegex.FindString(identifier)
+	},
+}
+
+func ReleaseAssetsNamedForRelease(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	data, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	releases, err := data.Releases()
+	if err != nil {
+		return gemara.Unknown, err.Error(), confidence
+	}
+
+	normalizations, err := configuredNormalizations(data)
+	if err != nil {
+		return gemara.Unknown, err.Error(), confidence
+	}
+	var assetCount int
+	var offenders []string
+	for _, release := range releases {
+		identifiers := releaseIdentifiers(release, normalizations)
+		var badAssets []string
+		for _, asset := range release.Assets {
+			assetCount++
+			if !assetNamedForRelease(asset.Name, identifiers) {
+				badAssets = append(badAssets, asset.Name)
+			}
+		}
+		if len(badAssets) > 0 {
+			offenders = append(offenders, fmt.Sprintf("%s (%s)", release.TagName, strings.Join(badAssets, ", ")))
+		}
+	}
+
+	if assetCount == 0 {
+		return gemara.NotApplicable, "No release assets found", confidence
+	}
+	if len(offenders) > 0 {
+		return gemara.Failed, fmt.Sprintf("Release assets do not include the release identifier in their name: %s", strings.Join(offenders, "; ")), confidence
+	}
+	return gemara.Passed, fmt.Sprintf("All %d release assets include the release identifier in their name", assetCount), confidence
+}
+
+// configuredNormalizations returns the normalizations named in the config, or all of them when none are set.
+// An unrecognized name is an error rather than ignored, so that a typo does not silently turn a normalization off.
+func configuredNormalizations(data data.Payload) ([]string, error) {
+	known := slices.Sorted(maps.Keys(releaseAssetNormalizations))
+	normalizations := reusable_steps.GetConfigList(data, "release-asset-normalizations")
+	if len(normalizations) == 0 {
+		return known, nil
+	}
+	var unknown []string
+	for _, name := range normalizations {
+		if _, ok := releaseAssetNormalizations[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unrecognized release-asset-normalizations %s (expected %s)", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return normalizations, nil
+}
+
+// minReleaseIdentifierLength keeps identifiers such as the "1" normalized from tag "v1" from matching unrelated assets
+const minReleaseIdentifierLength = 2
+
+// releaseIdentifiers returns the tag, the name and any normalized forms of them that identify the release
+func releaseIdentifiers(release data.ReleaseData, normalizations []string) (identifiers []string) {
+	add := func(identifier string) {
+		if len(identifier) >= minReleaseIdentifierLength {
+			identifiers = append(identifiers, identifier)
+		}
+	}
+	for _, identifier := range []string{release.TagName, release.Name} {
+		if identifier == "" {
+			continue
+		}
+		add(identifier)
+		for _, name := range normalizations {
+			add(releaseAssetNormalizations[name](identifier))
+		}
+	}
+	return identifiers
+}
+
+// assetNamedForRelease reports whether any identifier appears in the asset name as a whole token,
+// delimited by the start or end of the name or by one of - _ . /
+func assetNamedForRelease(assetName string, identifiers []string) bool {
+	name := strings.ToLower(assetName)
+	for _, identifier := range identifiers {
+		identifier = strings.ToLower(identifier)
+		for offset := 0; ; {
+			index := strings.Index(name[offset:], identifier)
+			if index < 0 {
+				break
+			}
+			start := offset + index
+			end := start + len(identifier)
+			if (start == 0 || isAssetNameSeparator(name[start-1])) && (end == len(name) || isAssetNameSeparator(name[end])) {
+				return true
+			}
+			offset = start + 1
+		}
+	}
+	return false
+}
+
+func isAssetNameSeparator(c byte) bool {
+	return c == '-' || c == '_' || c == '.' || c == '/'
+}
+
 func getLinks(data data.Payload) []string {
 	ins := data.Insights
 	var links []string
@@ -324,11 +700,255 @@ func SecretScanningInUse(payloadData any) (result gemara.Result, message string,
 		return gemara.Unknown, message, confidence
 	}
 
-	if data.SecurityPosture.PreventsPushingSecrets() && data.SecurityPosture.ScansForSecrets() {
+	posture, err := data.SecurityPosture()
+	if err != nil {
+		return gemara.Unknown, fmt.Sprintf("Failed to determine the repository's security posture: %s", err.Error()), confidence
+	}
+
+	if posture.PreventsPushingSecrets() && posture.ScansForSecrets() {
 		return gemara.Passed, "Secret scanning is enabled and prevents pushing secrets", confidence
-	} else if data.SecurityPosture.PreventsPushingSecrets() || data.SecurityPosture.ScansForSecrets() {
+	} else if posture.PreventsPushingSecrets() || posture.ScansForSecrets() {
 		return gemara.Failed, "Secret scanning is only partially enabled", confidence
 	} else {
 		return gemara.Failed, "Secret scanning is not enabled", confidence
 	}
 }
+
+func SecretsPolicyDefined(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	payload, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	posture, err := payload.SecurityPosture()
+	if err != nil {
+		return gemara.Unknown, fmt.Sprintf("Failed to determine the repository's security posture: %s", err.Error()), confidence
+	}
+	if !posture.DefinesPolicyForHandlingSecrets() {
+		return gemara.Failed, "No policy for storing, accessing, or rotating secrets was found in the repository documentation or Security Insights data", confidence
+	}
+
+	policy := posture.SecretsPolicy()
+	if policy.Source == data.SecretsPolicySourceInsights {
+		return gemara.Passed, fmt.Sprintf("Secrets management policy was specified in Security Insights data (%s)", policy.Location), confidence
+	}
+	if len(policy.Topics) == 0 {
+		return gemara.Failed, fmt.Sprintf("A secrets section was found in %s, but it does not cover storing, accessing, or rotating secrets", policy.Location), confidence
+	}
+
+	var missing []string
+	for _, topic := range data.SecretsTopics {
+		if !slices.Contains(policy.Topics, topic) {
+			missing = append(missing, topic)
+		}
+	}
+	if len(missing) > 0 {
+		return gemara.NeedsReview, fmt.Sprintf("Secrets management policy in %s covers %s secrets but not %s secrets", policy.Location, strings.Join(policy.Topics, ", "), strings.Join(missing, ", ")), confidence
+	}
+
+	return gemara.Passed, fmt.Sprintf("Secrets management policy in %s covers %s secrets", policy.Location, strings.Join(policy.Topics, ", ")), confidence
+}
+
+// ingestionEcosystem describes how a package ecosystem pins its dependencies and installs them from the pinned versions
+type ingestionEcosystem struct {
+	name      string
+	manifests []string
+	lockfiles []string
+	// installCommand matches a workflow command that installs dependencies exactly as the lockfile pins them
+	installCommand *regexp.Regexp
+	// pinnedInstallCommand matches a workflow command that refuses unpinned dependencies,
+	// which makes a manifest that is also listed as a lockfile act as its own lockfile
+	pinnedInstallCommand *regexp.Regexp
+	example              string
+}
+
+// ingestionEcosystems are the ecosystems whose dependency ingestion can be evaluated.
+// A pinned requirements.txt acts as the lockfile of another Python manifest in its directory,
+// and as its own only when a workflow installs it with --require-hashes.
+var ingestionEcosystems = []ingestionEcosystem{
+	{
+		name:           "npm",
+		manifests:      []string{"package.json"},
+		lockfiles:      []string{"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lock", "bun.lockb"},
+		installCommand: regexp.MustCompile(`\bnpm\s+ci\b|\b(yarn|pnpm|bun)\s+install\b.*--(frozen-lockfile|immutable)`),
+		example:        "npm ci",
+	},
+	{
+		name:           "go",
+		manifests:      []string{"go.mod"},
+		lockfiles:      []string{"go.sum"},
+		installCommand: regexp.MustCompile(`\bgo\s+(mod\s+(download|verify)|build|test|install)\b`),
+		example:        "go mod download",
+	},
+	{
+		name:                 "python",
+		manifests:            []string{"requirements.txt", "pyproject.toml", "setup.py", "Pipfile"},
+		lockfiles:            []string{"requirements.txt", "Pipfile.lock", "poetry.lock", "uv.lock", "pdm.lock"},
+		installCommand:       regexp.MustCompile(`\bpip3?\s+install\b.*--require-hashes|\bpipenv\s+(sync|install\b.*--deploy)|\bpoetry\s+(install|sync)\b|\buv\s+sync\b.*--(locked|frozen)|\bpdm\s+sync\b|\bpip-sync\b`),
+		pinnedInstallCommand: regexp.MustCompile(`\bpip3?\s+install\b.*--require-hashes`),
+		example:              "pip install --require-hashes",
+	},
+	{
+		name:           "cargo",
+		manifests:      []string{"Cargo.toml"},
+		lockfiles:      []string{"Cargo.lock"},
+		installCommand: regexp.MustCompile(`\bcargo\s+\w+\b.*--(locked|frozen)`),
+		example:        "cargo build --locked",
+	},
+	{
+		name:           "bundler",
+		manifests:      []string{"Gemfile"},
+		lockfiles:      []string{"Gemfile.lock"},
+		installCommand: regexp.MustCompile(`\bbundle\s+install\b.*--(frozen|deployment)|\bbundle\s+config\b.*\b(frozen|deployment)\b|BUNDLE_(FROZEN|DEPLOYMENT)`),
+		example:        "bundle install --frozen",
+	},
+	{
+		name:           "composer",
+		manifests:      []string{"composer.json"},
+		lockfiles:      []string{"composer.lock"},
+		installCommand: regexp.MustCompile(`\bcomposer\s+install\b`),
+		example:        "composer install",
+	},
+}
+
+// ingestionFinding is the evaluation of one manifest of an ingestionEcosystem
+type ingestionFinding struct {
+	ecosystem     ingestionEcosystem
+	manifest      string
+	hasLockfile   bool
+	hasInstallCmd bool
+}
+
+func (f ingestionFinding) String() string {
+	return fmt.Sprintf("%s (%s)", f.ecosystem.name, f.manifest)
+}
+
+func StandardizedDependencyIngestion(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	payload, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	_, manifests, err := payload.DependencyManifests()
+	if err != nil {
+		return gemara.Unknown, fmt.Sprintf("Failed to query the GitHub dependency graph API: %s", err.Error()), confidence
+	}
+	if len(manifests) == 0 {
+		return gemara.NeedsReview, "No dependency manifests found in the GitHub dependency graph API. Review project to ensure dependencies are ingested with standardized tooling.", confidence
+	}
+
+	// Without workflows the lockfiles are still evaluated, and every ecosystem lacks an install command
+	var commands []string
+	workflows, result, message := reusable_steps.ParseWorkflows(payload)
+	if message != "" && result != gemara.NotApplicable {
+		return gemara.Unknown, fmt.Sprintf("Failed to parse workflows for dependency install commands: %s", message), confidence
+	}
+	for _, workflow := range workflows {
+		for _, job := range workflow.Jobs {
+			for _, step := range job.Steps {
+				if run, ok := step.Exec.(*actionlint.ExecRun); ok && run.Run != nil {
+					commands = append(commands, run.Run.Value)
+				}
+			}
+		}
+	}
+
+	tree, err := payload.Tree()
+	if err != nil {
+		return gemara.Unknown, fmt.Sprintf("Failed to list repository files for lockfiles: %s", err.Error()), confidence
+	}
+	repoFiles := make(map[string]bool)
+	for _, entry := range tree {
+		if entry.Type == "blob" {
+			repoFiles[entry.Path] = true
+		}
+	}
+	lockfileExists := func(path string) bool { return repoFiles[path] }
+	findings, unevaluated := checkDependencyIngestion(manifests, commands, lockfileExists)
+	if len(findings) == 0 {
+		return gemara.NeedsReview, fmt.Sprintf("Dependency ingestion could not be evaluated for these manifests: %s", strings.Join(unevaluated, ", ")), confidence
+	}
+
+	var missingLockfiles, missingCommands, standardized []string
+	for _, finding := range findings {
+		switch {
+		case !finding.hasLockfile:
+			missingLockfiles = append(missingLockfiles, finding.String())
+		case !finding.hasInstallCmd:
+			missingCommands = append(missingCommands, fmt.Sprintf("%s, e.g. `%s`", finding, finding.ecosystem.example))
+		default:
+			standardized = append(standardized, finding.String())
+		}
+	}
+
+	if len(missingLockfiles) > 0 {
+		return gemara.Failed, fmt.Sprintf("No lockfile was found for these dependency manifests: %s", strings.Join(missingLockfiles, ", ")), confidence
+	}
+	if len(missingCommands) > 0 {
+		return gemara.NeedsReview, fmt.Sprintf("Lockfiles were found, but no workflow installs dependencies from them with a standard command: %s", strings.Join(missingCommands, "; ")), confidence
+	}
+	message = fmt.Sprintf("Dependencies are pinned by lockfiles and installed with standard commands: %s", strings.Join(standardized, ", "))
+	if len(unevaluated) > 0 {
+		message = fmt.Sprintf("%s (not evaluated: %s)", message, strings.Join(unevaluated, ", "))
+	}
+	return gemara.Passed, message, confidence
+}
+
+// checkDependencyIngestion evaluates each manifest of a known ecosystem for a lockfile in the same directory
+// and a workflow command that installs from it. A manifest is only its own lockfile when a workflow installs it
+// with a command that refuses unpinned dependencies. Manifests of other ecosystems are returned as unevaluated.
+func checkDependencyIngestion(manifests []data.ManifestNode, commands []string, lockfileExists func(path string) bool) (findings []ingestionFinding, unevaluated []string) {
+	graphFiles := make(map[string]bool)
+	for _, manifest := range manifests {
+		graphFiles[manifest.Filename] = true
+	}
+
+	for _, manifest := range manifests {
+		dir, name := path.Split(manifest.Filename)
+		ecosystem, ok := ingestionEcosystemForManifest(name)
+		if !ok {
+			if slices.ContainsFunc(ingestionEcosystems, func(e ingestionEcosystem) bool { return slices.Contains(e.lockfiles, name) }) {
+				continue
+			}
+			if strings.HasPrefix(manifest.Filename, ".github/workflows/") {
+				continue
+			}
+			if packageManager := manifest.PackageManager(); packageManager != "" {
+				unevaluated = append(unevaluated, fmt.Sprintf("%s (%s)", manifest.Filename, packageManager))
+			} else {
+				unevaluated = append(unevaluated, manifest.Filename)
+			}
+			continue
+		}
+
+		finding := ingestionFinding{ecosystem: ecosystem, manifest: manifest.Filename}
+		for _, lockfile := range ecosystem.lockfiles {
+			lockfilePath := dir + lockfile
+			if lockfilePath == manifest.Filename {
+				if ecosystem.pinnedInstallCommand != nil && slices.ContainsFunc(commands, ecosystem.pinnedInstallCommand.MatchString) {
+					finding.hasLockfile = true
+					break
+				}
+				continue
+			}
+			if graphFiles[lockfilePath] || lockfileExists(lockfilePath) {
+				finding.hasLockfile = true
+				break
+			}
+		}
+		finding.hasInstallCmd = slices.ContainsFunc(commands, ecosystem.installCommand.MatchString)
+		findings = append(findings, finding)
+	}
+	return findings, unevaluated
+}
+
+// ingestionEcosystemForManifest returns the ecosystem of a manifest file name.
+// A file that is both a manifest and a lockfile, like requirements.txt, is treated as a manifest.
+func ingestionEcosystemForManifest(name string) (ingestionEcosystem, bool) {
+	for _, ecosystem := range ingestionEcosystems {
+		if slices.Contains(ecosystem.manifests, name) {
+			return ecosystem, true
+		}
+	}
+	return ingestionEcosystem{}, false
+}
@@ -1,13 +1,20 @@
 package build_release
 
 import (
+	"encoding/base64"
 	"fmt"
 	"regexp"
 	"slices"
+	"strings"
 	"testing"
 
+	"github.com/gemaraproj/go-gemara"
+	"github.com/google/go-github/v74/github"
+	"github.com/privateerproj/privateer-sdk/config"
 	"github.com/rhysd/actionlint"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/ossf/pvtr-github-repo-scanner/data"
 )
 
 var goodWorkflowFile = `name: OSPS Baseline Scan
@@ -158,3 +165,500 @@ func TestUnTrustedVarsRegex(t *testing.T) {
 	assert.Equal(t, expression.Match([]byte("github.event.issue.title")), true, "regex match failed")
 	assert.Equal(t, expression.Match([]byte("github.event.commits.arbitrary.data.message")), true, "regex match failed")
 }
+
+func TestCheckWorkflowFileForUnsanitizedBranchNames(t *testing.T) {
+	tests := []struct {
+		name         string
+		workflowFile string
+		wantOk       bool
+		wantMessage  []string
+	}{
+		{
+			name: "branch name interpolated into run script",
+			workflowFile: `on: pull_request
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "${{ github.head_ref }}"`,
+			wantOk:      false,
+			wantMessage: []string{"github.head_ref is interpolated into a run script in job 'build'"},
+		},
+		{
+			name: "branch name passed to github-script",
+			workflowFile: `on: pull_request
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/github-script@v7
+        with:
+          script: console.log("${{ github.event.pull_request.head.ref }}")`,
+			wantOk:      false,
+			wantMessage: []string{"github.event.pull_request.head.ref is passed to the 'script' input of actions/github-script@v7 in job 'build'"},
+		},
+		{
+			name: "branch name in env used with quotes",
+			workflowFile: `on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    env:
+      BRANCH: ${{ github.ref_name }}
+    steps:
+      - run: echo "Building $BRANCH" && git checkout "${BRANCH}"`,
+			wantOk: true,
+		},
+		{
+			name: "branch name in env used without quotes",
+			workflowFile: `on: push
+env:
+  BRANCH: ${{ github.ref_name }}
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: git checkout $BRANCH`,
+			wantOk:      false,
+			wantMessage: []string{"$BRANCH is used without quotes in a run script in job 'build'"},
+		},
+		{
+			name: "branch name followed through step and job outputs",
+			workflowFile: `on: pull_request
+jobs:
+  setup:
+    runs-on: ubuntu-latest
+    outputs:
+      branch: ${{ steps.meta.outputs.branch }}
+    steps:
+      - id: meta
+        env:
+          HEAD: ${{ github.head_ref }}
+        run: echo "branch=$HEAD" >> "$GITHUB_OUTPUT"
+  build:
+    needs: setup
+    runs-on: ubuntu-latest
+    steps:
+      - run: make ${{ needs.setup.outputs.branch }}`,
+			wantOk:      false,
+			wantMessage: []string{"needs.setup.outputs.branch is interpolated into a run script in job 'build'"},
+		},
+		{
+			name: "env var whose name extends a tainted one",
+			workflowFile: `on: pull_request
+env:
+  BRANCH: ${{ github.head_ref }}
+  BRANCH_COUNT: "1"
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "${{ env.BRANCH_COUNT }}"`,
+			wantOk: true,
+		},
+		{
+			name: "base ref is not a branch name chosen by the contributor",
+			workflowFile: `on: pull_request
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: git fetch origin ${{ github.base_ref }}`,
+			wantOk: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow, errs := actionlint.Parse([]byte(tt.workflowFile))
+			assert.Empty(t, errs)
+			ok, message := checkWorkflowFileForUnsanitizedBranchNames(workflow)
+			assert.Equal(t, tt.wantOk, ok)
+			for _, want := range tt.wantMessage {
+				assert.Contains(t, message, want)
+			}
+		})
+	}
+}
+
+func TestInsideQuotes(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefix   string
+		expected bool
+	}{
+		{"no quotes", "echo ", false},
+		{"open double quote", `echo "value `, true},
+		{"closed double quote", `echo "value" `, false},
+		{"open single quote", `echo 'value `, true},
+		{"escaped double quote", `echo \"`, false},
+		{"double quote inside single quotes", `echo '"' `, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, insideQuotes(tt.prefix))
+		})
+	}
+}
+
+func TestReleaseAssetsNamedForRelease(t *testing.T) {
+	tests := []struct {
+		name        string
+		vars        map[string]any
+		releases    []data.ReleaseData
+		wantResult  gemara.Result
+		wantMessage string
+	}{
+		{
+			name: "assets include the tag name",
+			releases: []data.ReleaseData{
+				{TagName: "v1.2.3", Assets: []data.ReleaseAsset{{Name: "tool-v1.2.3-linux-amd64.tar.gz"}}},
+			},
+			wantResult:  gemara.Passed,
+			wantMessage: "All 1 release assets include the release identifier in their name",
+		},
+		{
+			name: "assets drop the v prefix",
+			releases: []data.ReleaseData{
+				{TagName: "v1.2.3", Assets: []data.ReleaseAsset{{Name: "tool_1.2.3_checksums.txt"}}},
+			},
+			wantResult:  gemara.Passed,
+			wantMessage: "All 1 release assets include the release identifier in their name",
+		},
+		{
+			name: "assets use only the semver of a prefixed tag",
+			releases: []data.ReleaseData{
+				{TagName: "release-2.0.0-rc.1", Assets: []data.ReleaseAsset{{Name: "tool-2.0.0-rc.1.zip"}}},
+			},
+			wantResult:  gemara.Passed,
+			wantMessage: "All 1 release assets include the release identifier in their name",
+		},
+		{
+			name: "assets include the release name",
+			releases: []data.ReleaseData{
+				{TagName: "20250101", Name: "winter", Assets: []data.ReleaseAsset{{Name: "tool-winter.zip"}}},
+			},
+			wantResult:  gemara.Passed,
+			wantMessage: "All 1 release assets include the release identifier in their name",
+		},
+		{
+			name: "single digit left by the v prefix does not match",
+			releases: []data.ReleaseData{
+				{TagName: "v1", Assets: []data.ReleaseAsset{{Name: "tool-linux-x86_64.tar.gz"}, {Name: "tool-v1-linux.tar.gz"}}},
+			},
+			wantResult:  gemara.Failed,
+			wantMessage: "Release assets do not include the release identifier in their name: v1 (tool-linux-x86_64.tar.gz)",
+		},
+		{
+			name: "version only matches on token boundaries",
+			releases: []data.ReleaseData{
+				{TagName: "1.2.3", Assets: []data.ReleaseAsset{{Name: "foo-11.2.3.tgz"}, {Name: "foo-1.2.3.tgz"}}},
+			},
+			wantResult:  gemara.Failed,
+			wantMessage: "Release assets do not include the release identifier in their name: 1.2.3 (foo-11.2.3.tgz)",
+		},
+		{
+			name: "short release name does not match inside words",
+			releases: []data.ReleaseData{
+				{TagName: "20250101", Name: "go", Assets: []data.ReleaseAsset{{Name: "cargo.zip"}}},
+			},
+			wantResult:  gemara.Failed,
+			wantMessage: "Release assets do not include the release identifier in their name: 20250101 (cargo.zip)",
+		},
+		{
+			name: "offending assets are listed per release",
+			releases: []data.ReleaseData{
+				{TagName: "v1.2.3", Assets: []data.ReleaseAsset{{Name: "tool.tar.gz"}, {Name: "tool-1.2.3.zip"}, {Name: "SHA256SUMS"}}},
+				{TagName: "v1.2.2", Assets: []data.ReleaseAsset{{Name: "tool.tar.gz"}}},
+			},
+			wantResult:  gemara.Failed,
+			wantMessage: "Release assets do not include the release identifier in their name: v1.2.3 (tool.tar.gz, SHA256SUMS); v1.2.2 (tool.tar.gz)",
+		},
+		{
+			name: "normalizations can be restricted by config",
+			vars: map[string]any{"release-asset-normalizations": "semver"},
+			releases: []data.ReleaseData{
+				{TagName: "v1.2", Assets: []data.ReleaseAsset{{Name: "tool-1.2.zip"}}},
+			},
+			wantResult:  gemara.Failed,
+			wantMessage: "Release assets do not include the release identifier in their name: v1.2 (tool-1.2.zip)",
+		},
+		{
+			name: "unrecognized normalization in config",
+			vars: map[string]any{"release-asset-normalizations": "semvar,v-prefix"},
+			releases: []data.ReleaseData{
+				{TagName: "v1.2.3", Assets: []data.ReleaseAsset{{Name: "tool-1.2.3.zip"}}},
+			},
+			wantResult:  gemara.Unknown,
+			wantMessage: "unrecognized release-asset-normalizations semvar (expected semver, v-prefix)",
+		},
+		{
+			name: "releases without assets",
+			releases: []data.ReleaseData{
+				{TagName: "v1.2.3"},
+			},
+			wantResult:  gemara.NotApplicable,
+			wantMessage: "No release assets found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := data.NewPayloadWithReleases(data.Payload{
+				Config:   &config.Config{Vars: tt.vars},
+				RestData: &data.RestData{},
+			}, tt.releases)
+			result, message, _ := ReleaseAssetsNamedForRelease(payload)
+			assert.Equal(t, tt.wantResult, result)
+			assert.Equal(t, tt.wantMessage, message)
+		})
+	}
+}
+
+type stubSecurityPosture struct {
+	secretsPolicy data.SecretsPolicy
+}
+
+func (s stubSecurityPosture) PreventsPushingSecrets() bool { return false }
+func (s stubSecurityPosture) ScansForSecrets() bool        { return false }
+func (s stubSecurityPosture) DefinesPolicyForHandlingSecrets() bool {
+	return s.secretsPolicy.Location != ""
+}
+func (s stubSecurityPosture) SecretsPolicy() data.SecretsPolicy { return s.secretsPolicy }
+
+func TestSecretsPolicyDefined(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      data.SecretsPolicy
+		wantResult  gemara.Result
+		wantMessage string
+	}{
+		{
+			name:        "no policy",
+			wantResult:  gemara.Failed,
+			wantMessage: "No policy for storing, accessing, or rotating secrets was found in the repository documentation or Security Insights data",
+		},
+		{
+			name:        "all topics covered",
+			policy:      data.SecretsPolicy{Source: data.SecretsPolicySourceDocument, Location: "SECURITY.md", Topics: data.SecretsTopics},
+			wantResult:  gemara.Passed,
+			wantMessage: "Secrets management policy in SECURITY.md covers storing, accessing, rotating secrets",
+		},
+		{
+			name:        "some topics missing",
+			policy:      data.SecretsPolicy{Source: data.SecretsPolicySourceDocument, Location: "CONTRIBUTING.md", Topics: []string{data.SecretsTopicStoring}},
+			wantResult:  gemara.NeedsReview,
+			wantMessage: "Secrets management policy in CONTRIBUTING.md covers storing secrets but not accessing, rotating secrets",
+		},
+		{
+			name:        "security insights link",
+			policy:      data.SecretsPolicy{Source: data.SecretsPolicySourceInsights, Location: "https://example.com/secrets"},
+			wantResult:  gemara.Passed,
+			wantMessage: "Secrets management policy was specified in Security Insights data (https://example.com/secrets)",
+		},
+		{
+			name:        "repository section covering no topics",
+			policy:      data.SecretsPolicy{Source: data.SecretsPolicySourceDocument, Location: "SECURITY.md"},
+			wantResult:  gemara.Failed,
+			wantMessage: "A secrets section was found in SECURITY.md, but it does not cover storing, accessing, or rotating secrets",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := data.NewPayloadWithSecurityPosture(data.Payload{}, stubSecurityPosture{secretsPolicy: tt.policy})
+			result, message, _ := SecretsPolicyDefined(payload)
+			assert.Equal(t, tt.wantResult, result)
+			assert.Equal(t, tt.wantMessage, message)
+		})
+	}
+}
+
+func TestCheckDependencyIngestion(t *testing.T) {
+	manifest := func(filename, packageManager string) data.ManifestNode {
+		node := data.ManifestNode{Filename: filename}
+		if packageManager != "" {
+			node.Dependencies.Nodes = []data.Dependency{{PackageManager: packageManager}}
+		}
+		return node
+	}
+
+	tests := []struct {
+		name            string
+		manifests       []data.ManifestNode
+		commands        []string
+		repoFiles       []string
+		wantFindings    []string
+		wantLockfile    []bool
+		wantInstallCmd  []bool
+		wantUnevaluated []string
+	}{
+		{
+			name:           "npm lockfile from the dependency graph installed with npm ci",
+			manifests:      []data.ManifestNode{manifest("web/package.json", "NPM"), manifest("web/package-lock.json", "NPM")},
+			commands:       []string{"cd web\nnpm ci\nnpm test"},
+			wantFindings:   []string{"npm (web/package.json)"},
+			wantLockfile:   []bool{true},
+			wantInstallCmd: []bool{true},
+		},
+		{
+			name:           "npm install is not a standard install command",
+			manifests:      []data.ManifestNode{manifest("package.json", "NPM"), manifest("yarn.lock", "NPM")},
+			commands:       []string{"npm install", "yarn install"},
+			wantFindings:   []string{"npm (package.json)"},
+			wantLockfile:   []bool{true},
+			wantInstallCmd: []bool{false},
+		},
+		{
+			name:           "go.sum found in the repository",
+			manifests:      []data.ManifestNode{manifest("go.mod", "GO")},
+			commands:       []string{"go mod download"},
+			repoFiles:      []string{"go.sum"},
+			wantFindings:   []string{"go (go.mod)"},
+			wantLockfile:   []bool{true},
+			wantInstallCmd: []bool{true},
+		},
+		{
+			name:           "lockfile in another directory does not count",
+			manifests:      []data.ManifestNode{manifest("tools/Cargo.toml", "RUST"), manifest("Cargo.lock", "RUST")},
+			commands:       []string{"cargo build --locked"},
+			wantFindings:   []string{"cargo (tools/Cargo.toml)"},
+			wantLockfile:   []bool{false},
+			wantInstallCmd: []bool{true},
+		},
+		{
+			name:           "pip requires hashes",
+			manifests:      []data.ManifestNode{manifest("pyproject.toml", "PIP"), manifest("requirements.txt", "PIP")},
+			commands:       []string{"pip install -r requirements.txt --require-hashes"},
+			wantFindings:   []string{"python (pyproject.toml)", "python (requirements.txt)"},
+			wantLockfile:   []bool{true, true},
+			wantInstallCmd: []bool{true, true},
+		},
+		{
+			name:           "hash-pinned requirements.txt is its own lockfile",
+			manifests:      []data.ManifestNode{manifest("requirements.txt", "PIP")},
+			commands:       []string{"pip install -r requirements.txt --require-hashes"},
+			repoFiles:      []string{"requirements.txt"},
+			wantFindings:   []string{"python (requirements.txt)"},
+			wantLockfile:   []bool{true},
+			wantInstallCmd: []bool{true},
+		},
+		{
+			name:           "requirements.txt installed without hashes is not its own lockfile",
+			manifests:      []data.ManifestNode{manifest("requirements.txt", "PIP")},
+			commands:       []string{"pip install -r requirements.txt", "poetry install"},
+			repoFiles:      []string{"requirements.txt"},
+			wantFindings:   []string{"python (requirements.txt)"},
+			wantLockfile:   []bool{false},
+			wantInstallCmd: []bool{true},
+		},
+		{
+			name:            "unknown ecosystems and workflows are not evaluated",
+			manifests:       []data.ManifestNode{manifest("pom.xml", "MAVEN"), manifest(".github/workflows/ci.yml", "ACTIONS")},
+			wantUnevaluated: []string{"pom.xml (MAVEN)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lockfileExists := func(path string) bool { return slices.Contains(tt.repoFiles, path) }
+			findings, unevaluated := checkDependencyIngestion(tt.manifests, tt.commands, lockfileExists)
+
+			var names []string
+			var lockfiles, installCmds []bool
+			for _, finding := range findings {
+				names = append(names, finding.String())
+				lockfiles = append(lockfiles, finding.hasLockfile)
+				installCmds = append(installCmds, finding.hasInstallCmd)
+			}
+			assert.Equal(t, tt.wantFindings, names)
+			assert.Equal(t, tt.wantLockfile, lockfiles)
+			assert.Equal(t, tt.wantInstallCmd, installCmds)
+			assert.Equal(t, tt.wantUnevaluated, unevaluated)
+		})
+	}
+}
+
+func TestStandardizedDependencyIngestion(t *testing.T) {
+	workflow := func(content string) *github.RepositoryContent {
+		return &github.RepositoryContent{
+			Name:     github.Ptr("ci.yml"),
+			Path:     github.Ptr(".github/workflows/ci.yml"),
+			Encoding: github.Ptr("base64"),
+			Content:  github.Ptr(base64.StdEncoding.EncodeToString([]byte(content))),
+		}
+	}
+	manifests := []data.ManifestNode{{Filename: "go.mod"}}
+
+	tests := []struct {
+		name        string
+		workflows   []*github.RepositoryContent
+		tree        []data.RepoTreeEntry
+		wantResult  gemara.Result
+		wantMessage string
+	}{
+		{
+			name:        "lockfile found in the tree and installed by a workflow",
+			workflows:   []*github.RepositoryContent{workflow("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: go mod download\n")},
+			tree:        []data.RepoTreeEntry{{Path: "go.mod", Type: "blob"}, {Path: "go.sum", Type: "blob"}},
+			wantResult:  gemara.Passed,
+			wantMessage: "Dependencies are pinned by lockfiles and installed with standard commands: go (go.mod)",
+		},
+		{
+			name:        "lockfile missing from the tree",
+			workflows:   []*github.RepositoryContent{workflow("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: go mod download\n")},
+			tree:        []data.RepoTreeEntry{{Path: "go.mod", Type: "blob"}, {Path: "go.sum", Type: "tree"}},
+			wantResult:  gemara.Failed,
+			wantMessage: "No lockfile was found for these dependency manifests: go (go.mod)",
+		},
+		{
+			name:        "workflow that cannot be parsed",
+			workflows:   []*github.RepositoryContent{workflow("jobs: [")},
+			tree:        []data.RepoTreeEntry{{Path: "go.sum", Type: "blob"}},
+			wantResult:  gemara.Unknown,
+			wantMessage: "Failed to parse workflows for dependency install commands: Error parsing workflow:",
+		},
+		{
+			name:        "no workflows",
+			tree:        []data.RepoTreeEntry{{Path: "go.sum", Type: "blob"}},
+			wantResult:  gemara.NeedsReview,
+			wantMessage: "Lockfiles were found, but no workflow installs dependencies from them with a standard command: go (go.mod), e.g. `go mod download`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := data.NewPayloadWithDependencyManifests(data.Payload{}, manifests)
+			payload = data.NewPayloadWithWorkflows(payload, tt.workflows)
+			payload = data.NewPayloadWithTree(payload, tt.tree)
+			result, message, _ := StandardizedDependencyIngestion(payload)
+			assert.Equal(t, tt.wantResult, result)
+			assert.True(t, strings.HasPrefix(message, tt.wantMessage), message)
+		})
+	}
+}
+
+func TestReferencesContext(t *testing.T) {
+	tests := []struct {
+		expression string
+		reference  string
+		expected   bool
+	}{
+		{"env.FOO", "env.FOO", true},
+		{"format('{0}', env.FOO)", "env.FOO", true},
+		{"env.FOOBAR", "env.FOO", false},
+		{"env.FOO-BAR", "env.FOO", false},
+		{"myenv.FOO", "env.FOO", false},
+		{"env.FOOBAR || env.FOO", "env.FOO", true},
+		{"steps.meta.outputs.branch", "steps.meta.outputs.", true},
+		{"steps.metadata.outputs.branch", "steps.meta.outputs.", false},
+		{"needs.setup.outputs.branch_name", "needs.setup.outputs.branch", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			assert.Equal(t, tt.expected, referencesContext(tt.expression, tt.reference))
+		})
+	}
+}
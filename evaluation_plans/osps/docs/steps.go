@@ -1,11 +1,36 @@
 package docs
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/gemaraproj/go-gemara"
 
+	"github.com/ossf/pvtr-github-repo-scanner/data"
 	"github.com/ossf/pvtr-github-repo-scanner/evaluation_plans/reusable_steps"
 )
 
+// EndOfSupportFiles are searched in order for a statement of which versions are supported
+var EndOfSupportFiles = []string{"security.md", "readme.md", "support.md"}
+
+// EndOfSupportHeadings are matched case insensitively against the headings of the EndOfSupportFiles
+var EndOfSupportHeadings = []string{
+	"supported versions",
+	"supported releases",
+	"version support",
+	"support policy",
+	"end of life",
+	"end-of-life",
+	"eol",
+	"end of support",
+	"end-of-support",
+	"lifecycle",
+}
+
+// endOfSupportColumns mark the column of a version table that records whether or until when a version is supported
+var endOfSupportColumns = []string{"supported", "support", "eol", "end of life", "end-of-life"}
+
 func HasSupportDocs(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
 	data, message := reusable_steps.VerifyPayload(payloadData)
 	if message != "" {
@@ -84,3 +109,88 @@ func HasIdentityVerificationGuide(payloadData any) (result gemara.Result, messag
 
 	return gemara.Passed, "Identity verification guide was specified in Security Insights data (found in signature-verification field)", confidence
 }
+
+func HasEndOfSupportStatement(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	payload, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	var documents []data.MarkdownDocument
+	for _, filename := range EndOfSupportFiles {
+		document, err := payload.GetMarkdownDocument(filename)
+		if err != nil {
+			payload.Config.Logger.Error(fmt.Sprintf("failed to read %s: %s", filename, err.Error()))
+			continue
+		}
+		if document.Path != "" {
+			documents = append(documents, document)
+		}
+	}
+
+	if statement := findEndOfSupportStatement(documents); statement != "" {
+		return gemara.Passed, fmt.Sprintf("End of support statement was found in %s", statement), confidence
+	}
+
+	if payload.Insights.Project.Documentation.SupportPolicy != nil {
+		return gemara.Passed, "Support policy was specified in Security Insights data", confidence
+	}
+
+	return gemara.Failed, "No supported versions section, version support table, or Security Insights support policy was found", confidence
+}
+
+// findEndOfSupportStatement returns a description of where the first end of support statement was found, or "" when there is none
+func findEndOfSupportStatement(documents []data.MarkdownDocument) string {
+	for _, document := range documents {
+		for _, heading := range document.Headings {
+			if isEndOfSupportHeading(heading) {
+				return fmt.Sprintf("%s under heading %q", document.Path, heading)
+			}
+		}
+		for _, header := range document.TableHeaders {
+			if isEndOfSupportTable(header) {
+				return fmt.Sprintf("%s in a table with columns %q", document.Path, strings.Join(header, ", "))
+			}
+		}
+	}
+	return ""
+}
+
+func isEndOfSupportHeading(heading string) bool {
+	heading = strings.ToLower(strings.TrimSpace(heading))
+	for _, candidate := range EndOfSupportHeadings {
+		if heading == candidate {
+			return true
+		}
+		// short terms like "eol" must match a whole word to avoid hits such as "geology"
+		if len(candidate) > 3 && strings.Contains(heading, candidate) {
+			return true
+		}
+		if slices.Contains(strings.FieldsFunc(heading, isHeadingSeparator), candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHeadingSeparator(r rune) bool {
+	return !(r >= 'a' && r <= 'z') && !(r >= '0' && r <= '9') && r != '-'
+}
+
+// isEndOfSupportTable returns true when a table has both a version column and a support or EOL column
+func isEndOfSupportTable(header []string) bool {
+	hasVersion, hasSupport := false, false
+	for _, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if strings.Contains(column, "version") || strings.Contains(column, "release") {
+			hasVersion = true
+			continue
+		}
+		for _, candidate := range endOfSupportColumns {
+			if strings.Contains(column, candidate) {
+				hasSupport = true
+			}
+		}
+	}
+	return hasVersion && hasSupport
+}
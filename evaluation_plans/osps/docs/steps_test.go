@@ -0,0 +1,72 @@
+package docs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ossf/pvtr-github-repo-scanner/data"
+)
+
+func TestFindEndOfSupportStatement(t *testing.T) {
+	tests := []struct {
+		name      string
+		documents []data.MarkdownDocument
+		expected  string
+	}{
+		{
+			name:      "no documents",
+			documents: nil,
+			expected:  "",
+		},
+		{
+			name: "supported versions heading",
+			documents: []data.MarkdownDocument{
+				{Path: "SECURITY.md", Headings: []string{"Security Policy", "Supported Versions"}},
+			},
+			expected: `SECURITY.md under heading "Supported Versions"`,
+		},
+		{
+			name: "end of life heading in readme",
+			documents: []data.MarkdownDocument{
+				{Path: "SECURITY.md", Headings: []string{"Reporting a Vulnerability"}},
+				{Path: "README.md", Headings: []string{"Installation", "End-of-Life Schedule"}},
+			},
+			expected: `README.md under heading "End-of-Life Schedule"`,
+		},
+		{
+			name: "eol abbreviation",
+			documents: []data.MarkdownDocument{
+				{Path: "SUPPORT.md", Headings: []string{"Release EOL dates"}},
+			},
+			expected: `SUPPORT.md under heading "Release EOL dates"`,
+		},
+		{
+			name: "eol inside another word is ignored",
+			documents: []data.MarkdownDocument{
+				{Path: "README.md", Headings: []string{"Geology samples"}},
+			},
+			expected: "",
+		},
+		{
+			name: "version support table",
+			documents: []data.MarkdownDocument{
+				{Path: "SECURITY.md", Headings: []string{"Security"}, TableHeaders: [][]string{{"Version", "Supported"}}},
+			},
+			expected: `SECURITY.md in a table with columns "Version, Supported"`,
+		},
+		{
+			name: "table without support column",
+			documents: []data.MarkdownDocument{
+				{Path: "README.md", TableHeaders: [][]string{{"Version", "Download"}}},
+			},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, findEndOfSupportStatement(tt.documents))
+		})
+	}
+}
@@ -1,10 +1,26 @@
 package governance
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/gemaraproj/go-gemara"
+	"github.com/ossf/pvtr-github-repo-scanner/data"
 	"github.com/ossf/pvtr-github-repo-scanner/evaluation_plans/reusable_steps"
 )
 
+// GovernanceFiles are searched in the root and forge directories for a collaborator access policy
+var GovernanceFiles = []string{"governance.md", "maintainers.md"}
+
+// privilegedRoleTerms identify a section heading about a role with elevated access to the repository
+var privilegedRoleTerms = []string{"maintainer", "committer", "commit access", "write access", "admin"}
+
+// accessGrantTerms identify a section that describes how a role or access is granted
+var accessGrantTerms = []string{"becom", "grant", "nominat", "promot", "elect", "appoint", "invite"}
+
+// accessReviewTerms identify a section in which granting access is reviewed or approved by others
+var accessReviewTerms = []string{"approv", "vote", "voting", "review", "consensus", "sponsor", "sign-off", "sign off"}
+
 func CoreTeamIsListed(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
 	data, message := reusable_steps.VerifyPayload(payloadData)
 	if message != "" {
@@ -70,7 +86,11 @@ func HasContributionReviewPolicy(payloadData any) (result gemara.Result, message
 	if message != "" {
 		return gemara.Unknown, message, confidence
 	}
-	if !data.IsCodeRepo {
+	isCodeRepo, err := data.IsCodeRepo()
+	if err != nil {
+		return gemara.Unknown, fmt.Sprintf("Failed to list repository languages: %s", err.Error()), confidence
+	}
+	if !isCodeRepo {
 		return gemara.NotApplicable, "Repository contains no code - skipping code contribution policy check", confidence
 	}
 	if data.Insights.Repository.Documentation.ReviewPolicy != nil {
@@ -79,3 +99,67 @@ func HasContributionReviewPolicy(payloadData any) (result gemara.Result, message
 
 	return gemara.Failed, "Code review guide was NOT specified in Security Insights data", confidence
 }
+
+func HasCollaboratorReviewPolicy(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	payload, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	var documents []data.MarkdownDocument
+	governanceLink := payload.Insights.Repository.Documentation.Governance
+	if governanceLink != nil {
+		path := payload.RepoFilePath(string(*governanceLink))
+		if strings.HasSuffix(strings.ToLower(path), ".md") {
+			document, err := payload.GetMarkdownDocumentByPath(path)
+			if err != nil {
+				payload.Config.Logger.Error(fmt.Sprintf("failed to read governance document %s: %s", path, err.Error()))
+			} else {
+				documents = append(documents, document)
+			}
+		}
+	}
+	for _, filename := range GovernanceFiles {
+		document, err := payload.GetMarkdownDocument(filename)
+		if err != nil {
+			payload.Config.Logger.Error(fmt.Sprintf("failed to read %s: %s", filename, err.Error()))
+			continue
+		}
+		if document.Path != "" {
+			documents = append(documents, document)
+		}
+	}
+
+	if path, heading := findAccessPolicySection(documents); path != "" {
+		return gemara.Passed, fmt.Sprintf("Policy for granting collaborator access was found in %s under heading %q", path, heading), confidence
+	}
+
+	if len(documents) > 0 {
+		return gemara.NeedsReview, fmt.Sprintf("Governance documentation was found in %s, but no section on granting maintainer, admin, or commit access was identified", documents[0].Path), confidence
+	}
+
+	if governanceLink != nil {
+		return gemara.NeedsReview, "Governance documentation was specified in Security Insights data, but could not be searched for a policy on granting collaborator access", confidence
+	}
+
+	return gemara.Failed, "No governance documentation was found in Security Insights data or the repository", confidence
+}
+
+// findAccessPolicySection returns the path and heading of the first section that describes how maintainer, admin,
+// or commit access is granted and reviewed. The heading must name a privileged role, so that a generic roles and
+// responsibilities section does not qualify.
+func findAccessPolicySection(documents []data.MarkdownDocument) (path, heading string) {
+	for _, document := range documents {
+		for _, section := range document.Sections {
+			title := strings.ToLower(section.Heading)
+			if !data.ContainsAny(title, privilegedRoleTerms) {
+				continue
+			}
+			text := title + "\n" + strings.ToLower(section.Body)
+			if data.ContainsAny(text, accessGrantTerms) && data.ContainsAny(text, accessReviewTerms) {
+				return document.Path, section.Heading
+			}
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,102 @@
+package governance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ossf/pvtr-github-repo-scanner/data"
+)
+
+func TestFindAccessPolicySection(t *testing.T) {
+	tests := []struct {
+		name            string
+		documents       []data.MarkdownDocument
+		expectedPath    string
+		expectedHeading string
+	}{
+		{
+			name:      "no documents",
+			documents: nil,
+		},
+		{
+			name: "becoming a maintainer",
+			documents: []data.MarkdownDocument{
+				{
+					Path: "GOVERNANCE.md",
+					Sections: []data.MarkdownSection{
+						{Heading: "Overview", Body: "This project is run by its maintainers.\n"},
+						{Heading: "Becoming a Maintainer", Body: "Contributors may be nominated by an existing maintainer and are approved by a majority of the maintainers.\n"},
+					},
+				},
+			},
+			expectedPath:    "GOVERNANCE.md",
+			expectedHeading: "Becoming a Maintainer",
+		},
+		{
+			name: "grant terms in section body",
+			documents: []data.MarkdownDocument{
+				{
+					Path: "MAINTAINERS.md",
+					Sections: []data.MarkdownSection{
+						{Heading: "Commit Access", Body: "Commit access is granted after a vote of the existing committers.\n"},
+					},
+				},
+			},
+			expectedPath:    "MAINTAINERS.md",
+			expectedHeading: "Commit Access",
+		},
+		{
+			name: "maintainer list without a policy",
+			documents: []data.MarkdownDocument{
+				{
+					Path: "MAINTAINERS.md",
+					Sections: []data.MarkdownSection{
+						{Heading: "Maintainers", Body: "Jane Doe\nJohn Doe\n"},
+					},
+				},
+			},
+		},
+		{
+			name: "generic roles and responsibilities section",
+			documents: []data.MarkdownDocument{
+				{
+					Path: "GOVERNANCE.md",
+					Sections: []data.MarkdownSection{
+						{Heading: "Roles and Responsibilities", Body: "Owners apply the requirements for access. Anyone added to the project reviews pull requests.\n"},
+					},
+				},
+			},
+		},
+		{
+			name: "maintainer duties without a process for granting access",
+			documents: []data.MarkdownDocument{
+				{
+					Path: "MAINTAINERS.md",
+					Sections: []data.MarkdownSection{
+						{Heading: "Maintainer Responsibilities", Body: "Maintainers review and approve pull requests.\n"},
+					},
+				},
+			},
+		},
+		{
+			name: "grant terms outside an access section",
+			documents: []data.MarkdownDocument{
+				{
+					Path: "GOVERNANCE.md",
+					Sections: []data.MarkdownSection{
+						{Heading: "Decision Making", Body: "Decisions are made by vote.\n"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, heading := findAccessPolicySection(tt.documents)
+			assert.Equal(t, tt.expectedPath, path)
+			assert.Equal(t, tt.expectedHeading, heading)
+		})
+	}
+}
@@ -68,7 +68,11 @@ func ReleasesLicensed(payloadData any) (result gemara.Result, message string, co
 		return gemara.Unknown, message, confidence
 	}
 
-	if len(data.Releases) == 0 {
+	releases, err := data.Releases()
+	if err != nil {
+		return gemara.Unknown, err.Error(), confidence
+	}
+	if len(releases) == 0 {
 		return gemara.NotApplicable, "No releases found", confidence
 	}
 	if data.Repository.LicenseInfo.Url == "" {
@@ -27,47 +27,37 @@ func stubGraphqlRepo(licenseUrl string) *data.GraphqlRepoData {
 func TestReleasesLicensed(t *testing.T) {
 	tests := []struct {
 		name            string
-		payloadData     any
+		payloadData     data.Payload
+		releases        []data.ReleaseData
 		expectedResult  gemara.Result
 		expectedMessage string
 	}{
 		{
 			name: "No releases found",
 			payloadData: data.Payload{
-				RestData: &data.RestData{
-					Releases: []data.ReleaseData{},
-				},
+				RestData: &data.RestData{},
 			},
+			releases:        []data.ReleaseData{},
 			expectedResult:  gemara.NotApplicable,
 			expectedMessage: "No releases found",
 		},
 		{
 			name: "No licenses found",
 			payloadData: data.Payload{
-				RestData: &data.RestData{
-					Releases: []data.ReleaseData{
-						{
-							Name: "v1.0.0",
-						},
-					},
-				},
+				RestData:        &data.RestData{},
 				GraphqlRepoData: &data.GraphqlRepoData{},
 			},
+			releases:        []data.ReleaseData{{Name: "v1.0.0"}},
 			expectedResult:  gemara.Failed,
 			expectedMessage: "License was not found in a well known location via the GitHub API",
 		},
 		{
 			name: "Has releases and license",
 			payloadData: data.Payload{
-				RestData: &data.RestData{
-					Releases: []data.ReleaseData{
-						{
-							Name: "v1.0.0",
-						},
-					},
-				},
+				RestData:        &data.RestData{},
 				GraphqlRepoData: stubGraphqlRepo("https://api.github.com/licenses/mit"),
 			},
+			releases:        []data.ReleaseData{{Name: "v1.0.0"}},
 			expectedResult:  gemara.Passed,
 			expectedMessage: "GitHub releases include the license(s) in the released source code.",
 		},
@@ -75,7 +65,8 @@ func TestReleasesLicensed(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, message, _ := ReleasesLicensed(test.payloadData)
+			payload := data.NewPayloadWithReleases(test.payloadData, test.releases)
+			result, message, _ := ReleasesLicensed(payload)
 			assert.Equal(t, test.expectedResult, result)
 			assert.Equal(t, test.expectedMessage, message)
 		})
@@ -1,10 +1,18 @@
 package quality
 
 import (
+	"bytes"
 	"fmt"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/gemaraproj/go-gemara"
+	"github.com/ossf/si-tooling/v2/si"
+
+	"github.com/ossf/pvtr-github-repo-scanner/data"
+	"github.com/ossf/pvtr-github-repo-scanner/evaluation_plans/osps/access_control"
+	"github.com/ossf/pvtr-github-repo-scanner/evaluation_plans/osps/build_release"
 	"github.com/ossf/pvtr-github-repo-scanner/evaluation_plans/reusable_steps"
 )
 
@@ -32,6 +40,107 @@ func InsightsListsRepositories(payloadData any) (result gemara.Result, message s
 	return gemara.Failed, "Insights does not contain a list of repositories", confidence
 }
 
+// parityCheck is a step that is run against the primary repository and each subproject to compare their security settings
+type parityCheck struct {
+	name string
+	step func(payloadData any) (gemara.Result, string, gemara.ConfidenceLevel)
+}
+
+var parityChecks = []parityCheck{
+	{name: "Branch protection", step: access_control.BranchProtectionRestrictsPushes},
+	{name: "Deletion protection", step: access_control.BranchProtectionPreventsDeletion},
+	{name: "Workflow permissions", step: access_control.WorkflowDefaultReadPermissions},
+	{name: "Non-author approval", step: RequiresNonAuthorApproval},
+	{name: "Secret scanning", step: build_release.SecretScanningInUse},
+}
+
+func SubprojectsMatchPrimarySecurity(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	payload, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	return compareSubprojects(payload, payload.Insights.Project.Repositories, payload.LoadSubprojectPayload)
+}
+
+// compareSubprojects runs the parity checks against the primary repository and every other listed repository,
+// failing when a subproject does not pass a check that the primary repository passes
+func compareSubprojects(primary data.Payload, repositories []si.ProjectRepository, load func(owner, repo string) (data.Payload, error)) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	primaryName := fmt.Sprintf("%s/%s", primary.Config.GetString("owner"), primary.Config.GetString("repo"))
+	rows := [][]gemara.Result{runParityChecks(primary)}
+	names := []string{primaryName}
+
+	var weaker, unevaluated []string
+	for _, repository := range repositories {
+		owner, repo, ok := primary.ParseRepositoryURL(string(repository.Url))
+		if !ok {
+			unevaluated = append(unevaluated, fmt.Sprintf("%s (not a GitHub repository)", repository.Url))
+			continue
+		}
+		name := fmt.Sprintf("%s/%s", owner, repo)
+		if slices.ContainsFunc(names, func(seen string) bool { return strings.EqualFold(seen, name) }) {
+			continue
+		}
+		subproject, err := load(owner, repo)
+		if err != nil {
+			unevaluated = append(unevaluated, fmt.Sprintf("%s (%s)", name, err.Error()))
+			continue
+		}
+
+		results := runParityChecks(subproject)
+		var weakerChecks []string
+		for i, check := range parityChecks {
+			if rows[0][i] == gemara.Passed && results[i] != gemara.Passed {
+				weakerChecks = append(weakerChecks, check.name)
+			}
+		}
+		if len(weakerChecks) > 0 {
+			weaker = append(weaker, fmt.Sprintf("%s (%s)", name, strings.Join(weakerChecks, ", ")))
+		}
+		names = append(names, name)
+		rows = append(rows, results)
+	}
+
+	if len(names) == 1 && len(unevaluated) == 0 {
+		return gemara.NotApplicable, "No subprojects other than the primary repository are listed in Security Insights data", confidence
+	}
+
+	table := parityTable(names, rows)
+	if len(weaker) > 0 {
+		return gemara.Failed, fmt.Sprintf("Subprojects have weaker security settings than the primary repository: %s\n%s", strings.Join(weaker, "; "), table), confidence
+	}
+	if len(unevaluated) > 0 {
+		return gemara.NeedsReview, fmt.Sprintf("Some subprojects could not be evaluated: %s\n%s", strings.Join(unevaluated, "; "), table), confidence
+	}
+	return gemara.Passed, fmt.Sprintf("All %d subprojects match the security settings of the primary repository\n%s", len(names)-1, table), confidence
+}
+
+func runParityChecks(payload data.Payload) (results []gemara.Result) {
+	for _, check := range parityChecks {
+		result, _, _ := check.step(payload)
+		results = append(results, result)
+	}
+	return results
+}
+
+// parityTable renders the results of each repository as a markdown table, with the primary repository first
+func parityTable(names []string, rows [][]gemara.Result) string {
+	var table strings.Builder
+	table.WriteString("| Repository |")
+	for _, check := range parityChecks {
+		table.WriteString(fmt.Sprintf(" %s |", check.name))
+	}
+	table.WriteString("\n|---|")
+	table.WriteString(strings.Repeat("---|", len(parityChecks)))
+	for i, name := range names {
+		table.WriteString(fmt.Sprintf("\n| %s |", name))
+		for _, result := range rows[i] {
+			table.WriteString(fmt.Sprintf(" %s |", result))
+		}
+	}
+	return table.String()
+}
+
 func StatusChecksAreRequiredByRulesets(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
 	data, message := reusable_steps.VerifyPayload(payloadData)
 	if message != "" {
@@ -49,14 +158,17 @@ func StatusChecksAreRequiredByRulesets(payloadData any) (result gemara.Result, m
 	}
 
 	// get the rules that apply to the default branch
-	rules := data.GetRulesets(data.Repository.DefaultBranchRef.Name)
+	rules, err := data.Rulesets(data.Repository.DefaultBranchRef.Name)
+	if err != nil {
+		data.Config.Logger.Error(err.Error())
+	}
 	if len(rules) == 0 {
 		return gemara.Passed, "No rulesets found for default branch, continuing to evaluate branch protection", confidence
 	}
 
 	// get the name of all required status checks
 	var requiredChecks []string
-	for _, rule := range data.Rulesets {
+	for _, rule := range rules {
 		for _, requiredCheck := range rule.Parameters.RequiredChecks {
 			requiredChecks = append(requiredChecks, requiredCheck.Context)
 		}
@@ -130,18 +242,118 @@ func NoBinariesInRepo(payloadData any) (result gemara.Result, message string, co
 		return gemara.Unknown, message, confidence
 	}
 
-	// TODO: This only checks the top 3 levels of the repository tree
-	// for common binary file extensions and it fails on very large repositories.
-	suspectedBinaries, err := data.GetSuspectedBinaries()
+	suspectedBinaries, unchecked, err := data.SuspectedBinaries()
 	if err != nil {
 		data.Config.Logger.Trace(fmt.Sprintf("unexpected response while checking for binaries: %s", err.Error()))
 		return gemara.Unknown, "Error while scanning repository for binaries, potentially due to repo size. See logs for details.", confidence
 	}
 
-	if len(suspectedBinaries) == 0 {
-		return gemara.Passed, "No common binary file extensions were found in the repository", confidence
+	if len(suspectedBinaries) > 0 {
+		return gemara.Failed, fmt.Sprintf("Suspected binaries found in the repository: %s", strings.Join(suspectedBinaries, ", ")), confidence
 	}
-	return gemara.Failed, fmt.Sprintf("Suspected binaries found in the repository: %s", strings.Join(suspectedBinaries, ", ")), confidence
+	if len(unchecked) > 0 {
+		return gemara.NeedsReview, "No binaries were found, but " + uncheckedBinariesSummary(unchecked), confidence
+	}
+	return gemara.Passed, "No common binary file extensions were found in the repository", confidence
+}
+
+// maxListedUncheckedFiles bounds how many unchecked files a message names
+const maxListedUncheckedFiles = 10
+
+// uncheckedBinariesSummary describes the files whose content could not be checked for being binary
+func uncheckedBinariesSummary(unchecked []string) string {
+	listed := unchecked[:min(len(unchecked), maxListedUncheckedFiles)]
+	summary := fmt.Sprintf("%d files could not be checked for binary content: %s", len(unchecked), strings.Join(listed, ", "))
+	if len(unchecked) > len(listed) {
+		summary += fmt.Sprintf(" and %d more", len(unchecked)-len(listed))
+	}
+	return summary
+}
+
+// ReviewableBinaryExtensions are binary formats whose content can be inspected without executing or unpacking it
+var ReviewableBinaryExtensions = []string{
+	".png", ".jpg", ".jpeg", ".gif", ".bmp", ".ico", ".icns", ".webp", ".tif", ".tiff", ".psd",
+	".ttf", ".otf", ".woff", ".woff2", ".eot",
+	".pdf", ".mp3", ".mp4", ".wav", ".ogg", ".webm",
+}
+
+// UnreviewableBinaryExtensions are executables, libraries, compiled objects and archives that hide their content from review
+var UnreviewableBinaryExtensions = []string{
+	".exe", ".dll", ".so", ".dylib", ".a", ".o", ".obj", ".lib", ".ko", ".elf", ".bin", ".wasm", ".node",
+	".class", ".jar", ".war", ".ear", ".aar", ".dex", ".apk",
+	".whl", ".egg", ".pyc", ".pyo", ".pyd",
+	".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".7z", ".rar", ".zst",
+	".deb", ".rpm", ".msi", ".dmg", ".iso", ".pkg",
+}
+
+// ReviewableBinaryPaths are directories where binaries are expected to be test fixtures.
+// More can be added with the comma separated binary-allowed-paths config var.
+var ReviewableBinaryPaths = []string{"testdata", "fixtures", "__fixtures__", "test-fixtures", "test_fixtures"}
+
+func NoUnreviewableBinariesInRepo(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	data, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	suspectedBinaries, unchecked, err := data.SuspectedBinaryPaths()
+	if err != nil {
+		data.Config.Logger.Trace(fmt.Sprintf("unexpected response while checking for binaries: %s", err.Error()))
+		return gemara.Unknown, "Error while scanning repository for binaries, potentially due to repo size. See logs for details.", confidence
+	}
+
+	allowedPaths := slices.Concat(ReviewableBinaryPaths, reusable_steps.GetConfigList(data, "binary-allowed-paths"))
+	var unreviewable, unclassified []string
+	for _, binaryPath := range suspectedBinaries {
+		switch classifyBinary(binaryPath, allowedPaths) {
+		case binaryUnreviewable:
+			unreviewable = append(unreviewable, binaryPath)
+		case binaryUnclassified:
+			unclassified = append(unclassified, binaryPath)
+		}
+	}
+
+	if len(unreviewable) > 0 {
+		return gemara.Failed, fmt.Sprintf("Unreviewable binaries found in the repository: %s", strings.Join(unreviewable, ", ")), confidence
+	}
+	if len(unclassified) > 0 {
+		return gemara.NeedsReview, fmt.Sprintf("Binaries of unknown type found in the repository: %s", strings.Join(unclassified, ", ")), confidence
+	}
+	if len(unchecked) > 0 {
+		return gemara.NeedsReview, "No unreviewable binaries were found, but " + uncheckedBinariesSummary(unchecked), confidence
+	}
+	return gemara.Passed, fmt.Sprintf("No unreviewable binaries were found in the repository (%d reviewable binaries found)", len(suspectedBinaries)), confidence
+}
+
+type binaryClass int
+
+const (
+	binaryReviewable binaryClass = iota
+	binaryUnreviewable
+	binaryUnclassified
+)
+
+// classifyBinary sorts a binary by its extension, treating anything under an allowed path as a reviewable fixture
+func classifyBinary(binaryPath string, allowedPaths []string) binaryClass {
+	lowerPath := strings.ToLower(binaryPath)
+	for _, allowed := range allowedPaths {
+		allowed = strings.Trim(strings.ToLower(allowed), "/")
+		if allowed == "" {
+			continue
+		}
+		if strings.HasPrefix(lowerPath, allowed+"/") || strings.Contains(lowerPath, "/"+allowed+"/") {
+			return binaryReviewable
+		}
+	}
+
+	ext := strings.ToLower(path.Ext(binaryPath))
+	if slices.Contains(UnreviewableBinaryExtensions, ext) {
+		return binaryUnreviewable
+	}
+	if slices.Contains(ReviewableBinaryExtensions, ext) {
+		return binaryReviewable
+	}
+	return binaryUnclassified
 }
 
 func RequiresNonAuthorApproval(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
@@ -213,7 +425,10 @@ func countDependencyManifests(payloadData any) (result gemara.Result, message st
 		return gemara.Unknown, message, confidence
 	}
 
-	manifestsCount := data.DependencyManifestsCount
+	manifestsCount, _, err := data.DependencyManifests()
+	if err != nil {
+		return gemara.Unknown, fmt.Sprintf("Failed to query the GitHub dependency graph API: %s", err.Error()), confidence
+	}
 	if manifestsCount > 0 {
 		return gemara.Passed, fmt.Sprintf("Found %d dependency manifests from GitHub API", manifestsCount), confidence
 	}
@@ -236,3 +451,108 @@ func DocumentsTestMaintenancePolicy(payloadData any) (result gemara.Result, mess
 	}
 	return gemara.NeedsReview, "Review project documentation to ensure it contains a clear policy for maintaining tests", confidence
 }
+
+// SbomFileSuffixes are file name endings used by SPDX and CycloneDX documents
+var SbomFileSuffixes = []string{
+	".spdx", ".spdx.json", ".spdx.yaml", ".spdx.yml", ".spdx.xml", ".spdx.rdf", ".spdx.tv",
+	".cdx.json", ".cdx.xml", ".bom.json", ".bom.xml",
+	"bom.json", "bom.xml",
+}
+
+// SbomFileNameHints are name fragments that identify an SBOM when paired with a document extension
+var SbomFileNameHints = []string{"sbom", "spdx", "cyclonedx"}
+
+// sbomFormatMarkers are strings found near the start of SPDX and CycloneDX documents
+var sbomFormatMarkers = []string{"spdxVersion", "SPDXVersion:", "spdx:SpdxDocument", `"bomFormat"`, "cyclonedx.org/schema/bom"}
+
+// maxSbomDownloadSize limits which release assets are downloaded to confirm their format
+const maxSbomDownloadSize = 5 * 1024 * 1024
+
+func ReleaseHasSbom(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	data, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	releases, err := data.Releases()
+	if err != nil {
+		return gemara.Unknown, err.Error(), confidence
+	}
+	if len(releases) == 0 {
+		return gemara.NotApplicable, "No releases found", confidence
+	}
+	latest := releases[0]
+
+	var unconfirmed []string
+	for _, asset := range latest.Assets {
+		if !isSbomFileName(asset.Name) {
+			continue
+		}
+		confirmed, checked := confirmSbomFormat(data, asset)
+		if confirmed {
+			return gemara.Passed, fmt.Sprintf("SBOM %s was found with release %s", asset.Name, latest.TagName), confidence
+		}
+		if !checked {
+			unconfirmed = append(unconfirmed, asset.Name)
+		}
+	}
+
+	for _, attestation := range data.Insights.Repository.ReleaseDetails.Attestations {
+		if isSbomAttestation(attestation.Name, attestation.PredicateURI) {
+			return gemara.Passed, fmt.Sprintf("SBOM attestation '%s' was declared in Security Insights data", attestation.Name), confidence
+		}
+	}
+
+	if len(unconfirmed) > 0 {
+		return gemara.NeedsReview, fmt.Sprintf("Possible SBOMs were found with release %s, but their format could not be confirmed: %s", latest.TagName, strings.Join(unconfirmed, ", ")), confidence
+	}
+	return gemara.Failed, fmt.Sprintf("No SBOM was found with release %s or declared in Security Insights data", latest.TagName), confidence
+}
+
+func isSbomFileName(name string) bool {
+	name = strings.ToLower(name)
+	for _, suffix := range SbomFileSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	if !strings.HasSuffix(name, ".json") && !strings.HasSuffix(name, ".xml") &&
+		!strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml") {
+		return false
+	}
+	for _, hint := range SbomFileNameHints {
+		if strings.Contains(name, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSbomAttestation(name string, predicateURI string) bool {
+	name = strings.ToLower(name)
+	predicateURI = strings.ToLower(predicateURI)
+	return strings.Contains(name, "sbom") ||
+		strings.Contains(predicateURI, "spdx.dev") ||
+		strings.Contains(predicateURI, "cyclonedx.org")
+}
+
+// confirmSbomFormat downloads small assets and looks for SPDX or CycloneDX markers.
+// checked is false when the asset could not be downloaded and its format is unknown.
+func confirmSbomFormat(data data.Payload, asset data.ReleaseAsset) (confirmed bool, checked bool) {
+	if asset.DownloadURL == "" || asset.Size > maxSbomDownloadSize {
+		return false, false
+	}
+	content, err := data.MakeApiCall(asset.DownloadURL, false)
+	if err != nil {
+		if data.Config != nil && data.Config.Logger != nil {
+			data.Config.Logger.Trace(fmt.Sprintf("failed to download release asset %s: %s", asset.Name, err.Error()))
+		}
+		return false, false
+	}
+	for _, marker := range sbomFormatMarkers {
+		if bytes.Contains(content, []byte(marker)) {
+			return true, true
+		}
+	}
+	return false, true
+}
@@ -1,9 +1,12 @@
 package quality
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/gemaraproj/go-gemara"
+	"github.com/privateerproj/privateer-sdk/config"
 	"github.com/ossf/si-tooling/v2/si"
 	"github.com/ossf/pvtr-github-repo-scanner/data"
 )
@@ -73,3 +76,330 @@ func Test_InsightsListsRepositories(t *testing.T) {
 		})
 	}
 }
+
+func Test_ReleaseHasSbom(t *testing.T) {
+	tests := []struct {
+		name         string
+		releases     []data.ReleaseData
+		attestations []si.Attestation
+		apiResponse  []byte
+		apiError     error
+		wantResult   gemara.Result
+		wantMsg      string
+	}{
+		{
+			name:       "no releases",
+			wantResult: gemara.NotApplicable,
+			wantMsg:    "No releases found",
+		},
+		{
+			name: "SPDX asset confirmed by content",
+			releases: []data.ReleaseData{
+				{TagName: "v1.0.0", Assets: []data.ReleaseAsset{
+					{Name: "tool-linux-amd64"},
+					{Name: "tool.spdx.json", DownloadURL: "https://example.com/tool.spdx.json"},
+				}},
+			},
+			apiResponse: []byte(`{"spdxVersion": "SPDX-2.3"}`),
+			wantResult:  gemara.Passed,
+			wantMsg:     "SBOM tool.spdx.json was found with release v1.0.0",
+		},
+		{
+			name: "CycloneDX asset confirmed by content",
+			releases: []data.ReleaseData{
+				{TagName: "v1.0.0", Assets: []data.ReleaseAsset{
+					{Name: "sbom.xml", DownloadURL: "https://example.com/sbom.xml"},
+				}},
+			},
+			apiResponse: []byte(`<bom xmlns="http://cyclonedx.org/schema/bom/1.5">`),
+			wantResult:  gemara.Passed,
+			wantMsg:     "SBOM sbom.xml was found with release v1.0.0",
+		},
+		{
+			name: "named like an SBOM but content is not",
+			releases: []data.ReleaseData{
+				{TagName: "v1.0.0", Assets: []data.ReleaseAsset{
+					{Name: "sbom.json", DownloadURL: "https://example.com/sbom.json"},
+				}},
+			},
+			apiResponse: []byte(`{"hello": "world"}`),
+			wantResult:  gemara.Failed,
+			wantMsg:     "No SBOM was found with release v1.0.0 or declared in Security Insights data",
+		},
+		{
+			name: "SBOM too large to confirm",
+			releases: []data.ReleaseData{
+				{TagName: "v1.0.0", Assets: []data.ReleaseAsset{
+					{Name: "tool.cdx.json", Size: 50 * 1024 * 1024, DownloadURL: "https://example.com/tool.cdx.json"},
+				}},
+			},
+			wantResult: gemara.NeedsReview,
+			wantMsg:    "Possible SBOMs were found with release v1.0.0, but their format could not be confirmed: tool.cdx.json",
+		},
+		{
+			name: "SBOM download fails",
+			releases: []data.ReleaseData{
+				{TagName: "v1.0.0", Assets: []data.ReleaseAsset{
+					{Name: "tool.cdx.json", DownloadURL: "https://example.com/tool.cdx.json"},
+				}},
+			},
+			apiError:   fmt.Errorf("connection refused"),
+			wantResult: gemara.NeedsReview,
+			wantMsg:    "Possible SBOMs were found with release v1.0.0, but their format could not be confirmed: tool.cdx.json",
+		},
+		{
+			name: "SBOM declared in Security Insights",
+			releases: []data.ReleaseData{
+				{TagName: "v1.0.0", Assets: []data.ReleaseAsset{{Name: "tool.tar.gz"}}},
+			},
+			attestations: []si.Attestation{
+				{Name: "Software bill of materials", PredicateURI: "https://spdx.dev/Document"},
+			},
+			wantResult: gemara.Passed,
+			wantMsg:    "SBOM attestation 'Software bill of materials' was declared in Security Insights data",
+		},
+		{
+			name: "no SBOM",
+			releases: []data.ReleaseData{
+				{TagName: "v1.0.0", Assets: []data.ReleaseAsset{{Name: "tool.tar.gz"}, {Name: "checksums.json"}}},
+			},
+			wantResult: gemara.Failed,
+			wantMsg:    "No SBOM was found with release v1.0.0 or declared in Security Insights data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := data.NewPayloadWithHTTPMock(data.Payload{
+				Config:   &config.Config{},
+				RestData: &data.RestData{},
+			}, tt.apiResponse, 200, tt.apiError)
+			payload = data.NewPayloadWithReleases(payload, tt.releases)
+			payload.Insights.Repository.ReleaseDetails.Attestations = tt.attestations
+
+			gotResult, gotMsg, _ := ReleaseHasSbom(payload)
+			if gotResult != tt.wantResult {
+				t.Errorf("result = %v, want %v", gotResult, tt.wantResult)
+			}
+			if gotMsg != tt.wantMsg {
+				t.Errorf("message = %q, want %q", gotMsg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func Test_classifyBinary(t *testing.T) {
+	tests := []struct {
+		name         string
+		binaryPath   string
+		allowedPaths []string
+		want         binaryClass
+	}{
+		{"image in docs is reviewable", "docs/images/logo.png", nil, binaryReviewable},
+		{"font is reviewable", "web/static/fonts/Inter.woff2", nil, binaryReviewable},
+		{"executable is unreviewable", "bin/tool.exe", nil, binaryUnreviewable},
+		{"shared library is unreviewable", "lib/libfoo.so", nil, binaryUnreviewable},
+		{"jar is unreviewable", "gradle/wrapper/gradle-wrapper.jar", nil, binaryUnreviewable},
+		{"wheel is unreviewable", "vendor/pkg-1.0-py3-none-any.whl", nil, binaryUnreviewable},
+		{"archive is unreviewable", "release.tar.gz", nil, binaryUnreviewable},
+		{"extension is case insensitive", "Setup.EXE", nil, binaryUnreviewable},
+		{"unknown binary is unclassified", "tools/protoc", nil, binaryUnclassified},
+		{"archive in allowed path is reviewable", "pkg/archive/testdata/sample.zip", ReviewableBinaryPaths, binaryReviewable},
+		{"allowed path at root is reviewable", "fixtures/app.exe", ReviewableBinaryPaths, binaryReviewable},
+		{"partial directory name is not allowed", "mytestdata/app.exe", ReviewableBinaryPaths, binaryUnreviewable},
+		{"configured path is reviewable", "third_party/blobs/app.jar", []string{"third_party/blobs/"}, binaryReviewable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyBinary(tt.binaryPath, tt.allowedPaths); got != tt.want {
+				t.Errorf("classifyBinary(%q) = %v, want %v", tt.binaryPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_BinaryStepsWithUncheckedFiles(t *testing.T) {
+	manyUnchecked := make([]string, maxListedUncheckedFiles+2)
+	for i := range manyUnchecked {
+		manyUnchecked[i] = fmt.Sprintf("tools/file%d", i)
+	}
+
+	tests := []struct {
+		name             string
+		binaries         []string
+		unchecked        []string
+		wantResult       gemara.Result
+		wantUnreviewable gemara.Result
+		wantMsgContains  string
+	}{
+		{
+			name:             "nothing found or unchecked",
+			wantResult:       gemara.Passed,
+			wantUnreviewable: gemara.Passed,
+		},
+		{
+			name:             "unchecked files need review",
+			unchecked:        []string{"tools/protoc"},
+			wantResult:       gemara.NeedsReview,
+			wantUnreviewable: gemara.NeedsReview,
+			wantMsgContains:  "1 files could not be checked for binary content: tools/protoc",
+		},
+		{
+			name:             "long unchecked list is truncated",
+			unchecked:        manyUnchecked,
+			wantResult:       gemara.NeedsReview,
+			wantUnreviewable: gemara.NeedsReview,
+			wantMsgContains:  "and 2 more",
+		},
+		{
+			name:             "found binaries outweigh unchecked files",
+			binaries:         []string{"bin/tool.exe"},
+			unchecked:        []string{"tools/protoc"},
+			wantResult:       gemara.Failed,
+			wantUnreviewable: gemara.Failed,
+			wantMsgContains:  "tool.exe",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := data.NewPayloadWithSuspectedBinaries(data.Payload{
+				Config: &config.Config{},
+			}, tt.binaries, tt.unchecked)
+
+			result, msg, _ := NoBinariesInRepo(payload)
+			if result != tt.wantResult {
+				t.Errorf("NoBinariesInRepo() result = %v, want %v (%s)", result, tt.wantResult, msg)
+			}
+			if !strings.Contains(msg, tt.wantMsgContains) {
+				t.Errorf("NoBinariesInRepo() message = %q, want it to contain %q", msg, tt.wantMsgContains)
+			}
+
+			result, msg, _ = NoUnreviewableBinariesInRepo(payload)
+			if result != tt.wantUnreviewable {
+				t.Errorf("NoUnreviewableBinariesInRepo() result = %v, want %v (%s)", result, tt.wantUnreviewable, msg)
+			}
+			if !strings.Contains(msg, tt.wantMsgContains) {
+				t.Errorf("NoUnreviewableBinariesInRepo() message = %q, want it to contain %q", msg, tt.wantMsgContains)
+			}
+		})
+	}
+}
+
+type paritySecurityPosture struct {
+	scansForSecrets bool
+}
+
+func (p paritySecurityPosture) PreventsPushingSecrets() bool          { return p.scansForSecrets }
+func (p paritySecurityPosture) ScansForSecrets() bool                 { return p.scansForSecrets }
+func (p paritySecurityPosture) DefinesPolicyForHandlingSecrets() bool { return false }
+func (p paritySecurityPosture) SecretsPolicy() data.SecretsPolicy     { return data.SecretsPolicy{} }
+
+func parityPayload(owner, repo string, protected, scansForSecrets bool) data.Payload {
+	graphql := &data.GraphqlRepoData{}
+	branch := &graphql.Repository.DefaultBranchRef
+	branch.BranchProtectionRule.RestrictsPushes = protected
+	branch.BranchProtectionRule.RequiresApprovingReviews = protected
+	branch.BranchProtectionRule.RequireLastPushApproval = protected
+	branch.RefUpdateRule.RequiredApprovingReviewCount = 1
+	branch.RefUpdateRule.AllowsDeletions = !protected
+
+	payload := data.NewPayloadWithSecurityPosture(data.Payload{
+		GraphqlRepoData:    graphql,
+		RestData:           &data.RestData{},
+		Config:             &config.Config{Vars: map[string]any{"owner": owner, "repo": repo}},
+		RepositoryMetadata: &data.GitHubRepositoryMetadata{},
+	}, paritySecurityPosture{scansForSecrets: scansForSecrets})
+	return data.NewPayloadWithWorkflowPermissions(payload, data.WorkflowPermissions{Enabled: true, DefaultPermissions: "read"})
+}
+
+func Test_compareSubprojects(t *testing.T) {
+	subprojects := map[string]data.Payload{
+		"org/strong": parityPayload("org", "strong", true, true),
+		"org/weak":   parityPayload("org", "weak", false, true),
+	}
+	load := func(owner, repo string) (data.Payload, error) {
+		payload, ok := subprojects[owner+"/"+repo]
+		if !ok {
+			return data.Payload{}, fmt.Errorf("not found")
+		}
+		return payload, nil
+	}
+	table := func(rows ...string) string {
+		return "| Repository | Branch protection | Deletion protection | Workflow permissions | Non-author approval | Secret scanning |\n" +
+			"|---|---|---|---|---|---|\n" + strings.Join(rows, "\n")
+	}
+
+	tests := []struct {
+		name         string
+		primary      data.Payload
+		repositories []string
+		wantResult   gemara.Result
+		wantMsg      string
+	}{
+		{
+			name:         "only the primary repository is listed",
+			primary:      parityPayload("org", "main", true, true),
+			repositories: []string{"https://github.com/org/main"},
+			wantResult:   gemara.NotApplicable,
+			wantMsg:      "No subprojects other than the primary repository are listed in Security Insights data",
+		},
+		{
+			name:         "subproject matches the primary repository",
+			primary:      parityPayload("org", "main", true, true),
+			repositories: []string{"https://github.com/org/main", "https://github.com/org/strong"},
+			wantResult:   gemara.Passed,
+			wantMsg: "All 1 subprojects match the security settings of the primary repository\n" + table(
+				"| org/main | Passed | Passed | Passed | Passed | Passed |",
+				"| org/strong | Passed | Passed | Passed | Passed | Passed |",
+			),
+		},
+		{
+			name:         "subproject is weaker than the primary repository",
+			primary:      parityPayload("org", "main", true, true),
+			repositories: []string{"https://github.com/org/strong", "https://github.com/org/weak.git"},
+			wantResult:   gemara.Failed,
+			wantMsg: "Subprojects have weaker security settings than the primary repository: org/weak (Branch protection, Deletion protection, Non-author approval)\n" + table(
+				"| org/main | Passed | Passed | Passed | Passed | Passed |",
+				"| org/strong | Passed | Passed | Passed | Passed | Passed |",
+				"| org/weak | Failed | Failed | Passed | Failed | Passed |",
+			),
+		},
+		{
+			name:         "weaker primary repository does not fail subprojects",
+			primary:      parityPayload("org", "main", false, false),
+			repositories: []string{"https://github.com/org/weak"},
+			wantResult:   gemara.Passed,
+			wantMsg: "All 1 subprojects match the security settings of the primary repository\n" + table(
+				"| org/main | Failed | Failed | Passed | Failed | Failed |",
+				"| org/weak | Failed | Failed | Passed | Failed | Passed |",
+			),
+		},
+		{
+			name:         "subprojects that cannot be loaded need review",
+			primary:      parityPayload("org", "main", true, true),
+			repositories: []string{"https://gitlab.com/org/other", "https://github.com/org/missing"},
+			wantResult:   gemara.NeedsReview,
+			wantMsg: "Some subprojects could not be evaluated: https://gitlab.com/org/other (not a GitHub repository); org/missing (not found)\n" + table(
+				"| org/main | Passed | Passed | Passed | Passed | Passed |",
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var repositories []si.ProjectRepository
+			for _, repoURL := range tt.repositories {
+				repositories = append(repositories, si.ProjectRepository{Url: si.URL(repoURL)})
+			}
+			gotResult, gotMsg, _ := compareSubprojects(tt.primary, repositories, load)
+			if gotResult != tt.wantResult {
+				t.Errorf("result = %v, want %v", gotResult, tt.wantResult)
+			}
+			if gotMsg != tt.wantMsg {
+				t.Errorf("message = %q, want %q", gotMsg, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -1,10 +1,15 @@
 package sec_assessment
 
 import (
+	"fmt"
+	"path"
+	"slices"
 	"strings"
 
 	"github.com/gemaraproj/go-gemara"
+	"github.com/ossf/si-tooling/v2/si"
 
+	"github.com/ossf/pvtr-github-repo-scanner/data"
 	"github.com/ossf/pvtr-github-repo-scanner/evaluation_plans/reusable_steps"
 )
 
@@ -36,14 +41,24 @@ func HasDesignDocumentation(payloadData any) (result gemara.Result, message stri
 
 	var foundDirectories []string
 
-	// Check for design documentation files and directories in repository root
-	if data.GraphqlRepoData != nil {
-		for _, entry := range data.Repository.Object.Tree.Entries {
+	// Check for design documentation files and directories anywhere outside third party code
+	if data.RestData != nil {
+		entries, err := data.Tree()
+		if err != nil {
+			data.Config.Logger.Trace(fmt.Sprintf("unexpected response while listing the repository tree: %s", err.Error()))
+			return gemara.Unknown, "Error while listing the repository tree, potentially due to repo size. See logs for details.", confidence
+		}
+		for _, entry := range entries {
+			if isThirdPartyPath(entry.Path) {
+				continue
+			}
+			name := path.Base(entry.Path)
+
 			// Check for design doc files (blobs only)
 			if entry.Type == "blob" {
 				for _, designFile := range DesignDocFiles {
-					if strings.EqualFold(entry.Name, designFile) {
-						return gemara.Passed, "Design documentation found: " + entry.Name, confidence
+					if strings.EqualFold(name, designFile) {
+						return gemara.Passed, "Design documentation found: " + entry.Path, confidence
 					}
 				}
 			}
@@ -51,8 +66,8 @@ func HasDesignDocumentation(payloadData any) (result gemara.Result, message stri
 			// Check for directories that typically contain design documentation
 			if entry.Type == "tree" {
 				for _, designDir := range DesignDocDirectories {
-					if strings.EqualFold(entry.Name, designDir) {
-						foundDirectories = append(foundDirectories, entry.Name)
+					if strings.EqualFold(name, designDir) {
+						foundDirectories = append(foundDirectories, entry.Path)
 					}
 				}
 			}
@@ -61,7 +76,7 @@ func HasDesignDocumentation(payloadData any) (result gemara.Result, message stri
 
 	// If we found directories that typically contain design docs, flag for manual review
 	if len(foundDirectories) > 0 {
-		return gemara.NeedsReview, "No design documentation file found in root, but found directories that may contain design documentation: " + strings.Join(foundDirectories, ", ") + " - manual review needed", confidence
+		return gemara.NeedsReview, "No design documentation file found, but found directories that may contain design documentation: " + strings.Join(foundDirectories, ", ") + " - manual review needed", confidence
 	}
 
 	// Fallback: check if DetailedGuide is specified in Security Insights
@@ -71,3 +86,233 @@ func HasDesignDocumentation(payloadData any) (result gemara.Result, message stri
 
 	return gemara.Failed, "Design documentation demonstrating all actions and actors was NOT found", confidence
 }
+
+// InterfaceSpecSuffixes are file name endings of machine-readable interface definitions:
+// protobuf/gRPC definitions and GraphQL schemas
+var InterfaceSpecSuffixes = []string{".proto", ".graphql", ".graphqls", ".gql"}
+
+// InterfaceSpecPrefixes are file name beginnings of OpenAPI, Swagger and AsyncAPI specs, which must also be JSON or YAML
+var InterfaceSpecPrefixes = []string{"openapi", "swagger", "asyncapi"}
+
+// InterfaceDocDirectories are directory paths that typically contain API or CLI reference documentation
+var InterfaceDocDirectories = []string{
+	"docs/api",
+	"doc/api",
+	"docs/api-reference",
+	"docs/reference",
+	"docs/cli",
+	"docs/commands",
+	"api-docs",
+}
+
+// InterfaceDocFiles are common file names for API or CLI reference documentation
+var InterfaceDocFiles = []string{
+	"api.md",
+	"api-reference.md",
+	"api.rst",
+	"cli.md",
+	"cli-reference.md",
+	"commands.md",
+	"usage.md",
+}
+
+// thirdPartyDirectories hold third party code whose documentation is not the project's own
+var thirdPartyDirectories = []string{"vendor", "node_modules", "third_party", "third-party", "external"}
+
+func HasExternalInterfaceDocumentation(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	data, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+	if data.RestData == nil {
+		return gemara.Unknown, "Repository data is not available in the payload", confidence
+	}
+
+	entries, err := data.Tree()
+	if err != nil {
+		data.Config.Logger.Trace(fmt.Sprintf("unexpected response while listing the repository tree: %s", err.Error()))
+		return gemara.Unknown, "Error while listing the repository tree, potentially due to repo size. See logs for details.", confidence
+	}
+
+	specs, docs := findInterfaceDocumentation(entries)
+	if len(specs) > 0 {
+		return gemara.Passed, "Machine-readable interface specifications found: " + strings.Join(specs, ", "), confidence
+	}
+	if len(docs) > 0 {
+		return gemara.NeedsReview, "No machine-readable interface specification found, but found reference documentation that may describe external interfaces: " + strings.Join(docs, ", ") + " - manual review needed", confidence
+	}
+	return gemara.Failed, "Documentation of external software interfaces was NOT found", confidence
+}
+
+// findInterfaceDocumentation returns the paths of machine-readable interface specifications
+// and of prose reference documentation in the repository tree
+func findInterfaceDocumentation(entries []data.RepoTreeEntry) (specs []string, docs []string) {
+	for _, entry := range entries {
+		if isThirdPartyPath(entry.Path) {
+			continue
+		}
+
+		lowerPath := strings.ToLower(entry.Path)
+		if entry.Type == "tree" {
+			for _, dir := range InterfaceDocDirectories {
+				if lowerPath == dir || strings.HasSuffix(lowerPath, "/"+dir) {
+					docs = append(docs, entry.Path+"/")
+					break
+				}
+			}
+			continue
+		}
+
+		name := path.Base(lowerPath)
+		if isInterfaceSpec(name) {
+			specs = append(specs, entry.Path)
+		} else if slices.Contains(InterfaceDocFiles, name) {
+			docs = append(docs, entry.Path)
+		}
+	}
+	return specs, docs
+}
+
+func isThirdPartyPath(entryPath string) bool {
+	return slices.ContainsFunc(strings.Split(strings.ToLower(entryPath), "/"), func(dir string) bool {
+		return slices.Contains(thirdPartyDirectories, dir)
+	})
+}
+
+func isInterfaceSpec(name string) bool {
+	for _, suffix := range InterfaceSpecSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	switch path.Ext(name) {
+	case ".json", ".yaml", ".yml":
+		for _, prefix := range InterfaceSpecPrefixes {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ThreatModelFiles are common file names for threat model documents
+var ThreatModelFiles = []string{
+	"threat_model.md",
+	"threat-model.md",
+	"threatmodel.md",
+	"threat_model.rst",
+	"threat-model.rst",
+	"threats.md",
+}
+
+func HasSecurityAssessment(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	data, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+	if data.RestData == nil {
+		return gemara.Unknown, "Repository data is not available in the payload", confidence
+	}
+
+	withEvidence, withoutEvidence := declaredAssessments(data.Insights)
+	if len(withEvidence) > 0 {
+		return gemara.Passed, "Security assessment with evidence was specified in Security Insights data: " + strings.Join(withEvidence, ", "), confidence
+	}
+
+	entries, err := data.Tree()
+	if err != nil {
+		data.Config.Logger.Trace(fmt.Sprintf("unexpected response while listing the repository tree: %s", err.Error()))
+		return gemara.Unknown, "Error while listing the repository tree, potentially due to repo size. See logs for details.", confidence
+	}
+	if threatModels := findThreatModels(entries); len(threatModels) > 0 {
+		return gemara.Passed, "Threat model found in the repository: " + strings.Join(threatModels, ", "), confidence
+	}
+
+	if len(withoutEvidence) > 0 {
+		return gemara.NeedsReview, "Security assessment was specified in Security Insights data without evidence: " + strings.Join(withoutEvidence, ", ") + " - manual review needed", confidence
+	}
+	return gemara.Failed, "No security assessment was specified in Security Insights data and no threat model was found in the repository", confidence
+}
+
+func HasThreatModel(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	data, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+	if data.RestData == nil {
+		return gemara.Unknown, "Repository data is not available in the payload", confidence
+	}
+
+	withEvidence, _ := declaredAssessments(data.Insights)
+	for _, assessment := range withEvidence {
+		if strings.Contains(strings.ToLower(assessment), "threat") {
+			return gemara.Passed, "Threat model was specified in Security Insights data: " + assessment, confidence
+		}
+	}
+
+	entries, err := data.Tree()
+	if err != nil {
+		data.Config.Logger.Trace(fmt.Sprintf("unexpected response while listing the repository tree: %s", err.Error()))
+		return gemara.Unknown, "Error while listing the repository tree, potentially due to repo size. See logs for details.", confidence
+	}
+	if threatModels := findThreatModels(entries); len(threatModels) > 0 {
+		return gemara.Passed, "Threat model found in the repository: " + strings.Join(threatModels, ", "), confidence
+	}
+
+	if len(withEvidence) > 0 {
+		return gemara.NeedsReview, "No threat model found, but security assessments were specified in Security Insights data: " + strings.Join(withEvidence, ", ") + " - manual review needed to confirm they include threat modeling and attack surface analysis", confidence
+	}
+	return gemara.Failed, "No threat model was found in the repository or Security Insights data", confidence
+}
+
+// declaredAssessments describes the self and third-party assessments in Security Insights data,
+// split by whether they link to evidence
+func declaredAssessments(insights si.SecurityInsights) (withEvidence []string, withoutEvidence []string) {
+	if insights.Repository == nil {
+		return nil, nil
+	}
+	describe := func(kind string, assessment si.Assessment) {
+		description := kind
+		if assessment.Name != nil && *assessment.Name != "" {
+			description = fmt.Sprintf("%s %q", kind, *assessment.Name)
+		} else if assessment.Comment != "" {
+			description = fmt.Sprintf("%s (%s)", kind, assessment.Comment)
+		}
+		if assessment.Evidence != nil && *assessment.Evidence != "" {
+			withEvidence = append(withEvidence, fmt.Sprintf("%s at %s", description, *assessment.Evidence))
+		} else {
+			withoutEvidence = append(withoutEvidence, description)
+		}
+	}
+
+	assessments := insights.Repository.SecurityPosture.Assessments
+	if assessments.Self.Comment != "" || assessments.Self.Name != nil || assessments.Self.Evidence != nil {
+		describe("self assessment", assessments.Self)
+	}
+	for _, assessment := range assessments.ThirdPartyAssessment {
+		describe("third-party assessment", assessment)
+	}
+	return withEvidence, withoutEvidence
+}
+
+// findThreatModels returns the paths of threat model documents, OWASP Threat Dragon models
+// and Microsoft Threat Modeling Tool (.tm7) models in the repository tree.
+// Threat Dragon models are plain JSON, so they are only recognized when their path mentions threats.
+func findThreatModels(entries []data.RepoTreeEntry) (threatModels []string) {
+	for _, entry := range entries {
+		if entry.Type != "blob" || isThirdPartyPath(entry.Path) {
+			continue
+		}
+		lowerPath := strings.ToLower(entry.Path)
+		name := path.Base(lowerPath)
+		switch {
+		case slices.Contains(ThreatModelFiles, name),
+			path.Ext(name) == ".tm7",
+			path.Ext(name) == ".json" && strings.Contains(lowerPath, "threat"):
+			threatModels = append(threatModels, entry.Path)
+		}
+	}
+	return threatModels
+}
+
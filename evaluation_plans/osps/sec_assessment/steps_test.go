@@ -1,11 +1,14 @@
 package sec_assessment
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
 	"github.com/gemaraproj/go-gemara"
+	"github.com/hashicorp/go-hclog"
 	"github.com/ossf/si-tooling/v2/si"
+	"github.com/privateerproj/privateer-sdk/config"
 
 	"github.com/ossf/pvtr-github-repo-scanner/data"
 )
@@ -16,6 +19,8 @@ func Test_HasDesignDocumentation(t *testing.T) {
 	tests := []struct {
 		name       string
 		payload    any
+		tree       []data.RepoTreeEntry
+		treeErr    error
 		wantResult gemara.Result
 		wantMsg    string
 	}{
@@ -37,25 +42,24 @@ func Test_HasDesignDocumentation(t *testing.T) {
 		{
 			name: "design doc file found",
 			payload: data.Payload{
-				GraphqlRepoData: buildGraphqlDataWithFiles([]string{DesignDocFiles[0], "README.md"}),
-				RestData:        &data.RestData{},
+				RestData: &data.RestData{},
 			},
+			tree:       treeWithFiles([]string{DesignDocFiles[0], "README.md"}),
 			wantResult: gemara.Passed,
 			wantMsg:    "Design documentation found: " + DesignDocFiles[0],
 		},
 		{
 			name: "design doc file found (case insensitive)",
 			payload: data.Payload{
-				GraphqlRepoData: buildGraphqlDataWithFiles([]string{strings.ToUpper(DesignDocFiles[1])}),
-				RestData:        &data.RestData{},
+				RestData: &data.RestData{},
 			},
+			tree:       treeWithFiles([]string{strings.ToUpper(DesignDocFiles[1])}),
 			wantResult: gemara.Passed,
 			wantMsg:    "Design documentation found: " + strings.ToUpper(DesignDocFiles[1]),
 		},
 		{
 			name: "no design file but DetailedGuide exists",
 			payload: data.Payload{
-				GraphqlRepoData: buildGraphqlDataWithFiles([]string{"README.md"}),
 				RestData: &data.RestData{
 					Insights: si.SecurityInsights{
 						Project: &si.Project{
@@ -66,13 +70,13 @@ func Test_HasDesignDocumentation(t *testing.T) {
 					},
 				},
 			},
+			tree:       treeWithFiles([]string{"README.md"}),
 			wantResult: gemara.NeedsReview,
 			wantMsg:    "No design documentation file found, but detailed guide specified in Security Insights - manual review needed to confirm design documentation with actions and actors",
 		},
 		{
 			name: "no design file and no DetailedGuide",
 			payload: data.Payload{
-				GraphqlRepoData: buildGraphqlDataWithFiles([]string{"README.md"}),
 				RestData: &data.RestData{
 					Insights: si.SecurityInsights{
 						Project: &si.Project{
@@ -81,16 +85,13 @@ func Test_HasDesignDocumentation(t *testing.T) {
 					},
 				},
 			},
+			tree:       treeWithFiles([]string{"README.md"}),
 			wantResult: gemara.Failed,
 			wantMsg:    "Design documentation demonstrating all actions and actors was NOT found",
 		},
 		{
 			name: "directory named like design file should not match",
 			payload: data.Payload{
-				GraphqlRepoData: buildGraphqlDataWithEntries([]fileEntry{
-					{Name: DesignDocFiles[0], Type: "tree"}, // directory, not a file
-					{Name: "README.md", Type: "blob"},
-				}),
 				RestData: &data.RestData{
 					Insights: si.SecurityInsights{
 						Project: &si.Project{
@@ -99,13 +100,16 @@ func Test_HasDesignDocumentation(t *testing.T) {
 					},
 				},
 			},
+			tree: treeWithEntries([]fileEntry{
+				{Name: DesignDocFiles[0], Type: "tree"}, // directory, not a file
+				{Name: "README.md", Type: "blob"},
+			}),
 			wantResult: gemara.Failed,
 			wantMsg:    "Design documentation demonstrating all actions and actors was NOT found",
 		},
 		{
 			name: "similar but non-matching file name should not match",
 			payload: data.Payload{
-				GraphqlRepoData: buildGraphqlDataWithFiles([]string{"ARCHITECTURE.pdf", "design.doc"}),
 				RestData: &data.RestData{
 					Insights: si.SecurityInsights{
 						Project: &si.Project{
@@ -114,62 +118,106 @@ func Test_HasDesignDocumentation(t *testing.T) {
 					},
 				},
 			},
+			tree:       treeWithFiles([]string{"ARCHITECTURE.pdf", "design.doc"}),
 			wantResult: gemara.Failed,
 			wantMsg:    "Design documentation demonstrating all actions and actors was NOT found",
 		},
 		{
 			name: "docs directory found - needs review",
 			payload: data.Payload{
-				GraphqlRepoData: buildGraphqlDataWithEntries([]fileEntry{
-					{Name: "docs", Type: "tree"},
-					{Name: "README.md", Type: "blob"},
-				}),
 				RestData: &data.RestData{},
 			},
+			tree: treeWithEntries([]fileEntry{
+				{Name: "docs", Type: "tree"},
+				{Name: "README.md", Type: "blob"},
+			}),
 			wantResult: gemara.NeedsReview,
-			wantMsg:    "No design documentation file found in root, but found directories that may contain design documentation: docs - manual review needed",
+			wantMsg:    "No design documentation file found, but found directories that may contain design documentation: docs - manual review needed",
 		},
 		{
 			name: "architecture directory found - needs review",
 			payload: data.Payload{
-				GraphqlRepoData: buildGraphqlDataWithEntries([]fileEntry{
-					{Name: "architecture", Type: "tree"},
-					{Name: "README.md", Type: "blob"},
-				}),
 				RestData: &data.RestData{},
 			},
+			tree: treeWithEntries([]fileEntry{
+				{Name: "architecture", Type: "tree"},
+				{Name: "README.md", Type: "blob"},
+			}),
 			wantResult: gemara.NeedsReview,
-			wantMsg:    "No design documentation file found in root, but found directories that may contain design documentation: architecture - manual review needed",
+			wantMsg:    "No design documentation file found, but found directories that may contain design documentation: architecture - manual review needed",
 		},
 		{
 			name: "multiple design directories found - needs review",
 			payload: data.Payload{
-				GraphqlRepoData: buildGraphqlDataWithEntries([]fileEntry{
-					{Name: "docs", Type: "tree"},
-					{Name: "design", Type: "tree"},
-					{Name: "README.md", Type: "blob"},
-				}),
 				RestData: &data.RestData{},
 			},
+			tree: treeWithEntries([]fileEntry{
+				{Name: "docs", Type: "tree"},
+				{Name: "design", Type: "tree"},
+				{Name: "README.md", Type: "blob"},
+			}),
 			wantResult: gemara.NeedsReview,
-			wantMsg:    "No design documentation file found in root, but found directories that may contain design documentation: docs, design - manual review needed",
+			wantMsg:    "No design documentation file found, but found directories that may contain design documentation: docs, design - manual review needed",
 		},
 		{
 			name: "design file takes precedence over directory",
 			payload: data.Payload{
-				GraphqlRepoData: buildGraphqlDataWithEntries([]fileEntry{
-					{Name: "docs", Type: "tree"},
-					{Name: "architecture.md", Type: "blob"},
-				}),
 				RestData: &data.RestData{},
 			},
+			tree: treeWithEntries([]fileEntry{
+				{Name: "docs", Type: "tree"},
+				{Name: "architecture.md", Type: "blob"},
+			}),
 			wantResult: gemara.Passed,
 			wantMsg:    "Design documentation found: architecture.md",
 		},
+		{
+			name: "deeply nested design doc file found",
+			payload: data.Payload{
+				RestData: &data.RestData{},
+			},
+			tree: treeWithEntries([]fileEntry{
+				{Name: "services/payments/internal/ledger/docs/adr/design.md", Type: "blob"},
+			}),
+			wantResult: gemara.Passed,
+			wantMsg:    "Design documentation found: services/payments/internal/ledger/docs/adr/design.md",
+		},
+		{
+			name: "vendored design doc file ignored",
+			payload: data.Payload{
+				RestData: &data.RestData{
+					Insights: si.SecurityInsights{
+						Project: &si.Project{
+							Documentation: &si.ProjectDocumentation{},
+						},
+					},
+				},
+			},
+			tree: treeWithEntries([]fileEntry{
+				{Name: "vendor/github.com/example/lib/design.md", Type: "blob"},
+			}),
+			wantResult: gemara.Failed,
+			wantMsg:    "Design documentation demonstrating all actions and actors was NOT found",
+		},
+		{
+			name: "tree listing error",
+			payload: data.Payload{
+				RestData: &data.RestData{},
+				Config:   &config.Config{Logger: hclog.NewNullLogger()},
+			},
+			treeErr:    errors.New("tree truncated"),
+			wantResult: gemara.Unknown,
+			wantMsg:    "Error while listing the repository tree, potentially due to repo size. See logs for details.",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			if payload, ok := tt.payload.(data.Payload); ok && tt.treeErr != nil {
+				tt.payload = data.NewPayloadWithTreeError(payload, tt.treeErr)
+			} else if ok && tt.tree != nil {
+				tt.payload = data.NewPayloadWithTree(payload, tt.tree)
+			}
 			gotResult, gotMsg, _ := HasDesignDocumentation(tt.payload)
 			if gotResult != tt.wantResult {
 				t.Errorf("HasDesignDocumentation() result = %v, want %v", gotResult, tt.wantResult)
@@ -181,22 +229,181 @@ func Test_HasDesignDocumentation(t *testing.T) {
 	}
 }
 
-// buildGraphqlDataWithFiles is a helper to create GraphqlRepoData with specified files
-func buildGraphqlDataWithFiles(fileNames []string) *data.GraphqlRepoData {
-	graphqlData := &data.GraphqlRepoData{}
+func Test_findInterfaceDocumentation(t *testing.T) {
+	tests := []struct {
+		name      string
+		entries   []data.RepoTreeEntry
+		wantSpecs []string
+		wantDocs  []string
+	}{
+		{
+			name: "machine-readable specs anywhere in the tree",
+			entries: []data.RepoTreeEntry{
+				{Path: "api", Type: "tree"},
+				{Path: "api/v1/service.proto", Type: "blob"},
+				{Path: "server/openapi.yaml", Type: "blob"},
+				{Path: "schema.graphql", Type: "blob"},
+				{Path: "main.go", Type: "blob"},
+			},
+			wantSpecs: []string{"api/v1/service.proto", "server/openapi.yaml", "schema.graphql"},
+		},
+		{
+			name: "prose reference docs only",
+			entries: []data.RepoTreeEntry{
+				{Path: "docs", Type: "tree"},
+				{Path: "docs/api", Type: "tree"},
+				{Path: "docs/api/index.md", Type: "blob"},
+				{Path: "website/docs/cli", Type: "tree"},
+				{Path: "CLI.md", Type: "blob"},
+			},
+			wantDocs: []string{"docs/api/", "website/docs/cli/", "CLI.md"},
+		},
+		{
+			name: "vendored interfaces are ignored",
+			entries: []data.RepoTreeEntry{
+				{Path: "vendor/github.com/org/lib/api.proto", Type: "blob"},
+				{Path: "node_modules/pkg/swagger.json", Type: "blob"},
+			},
+		},
+		{
+			name: "json files that are not specs",
+			entries: []data.RepoTreeEntry{
+				{Path: "package.json", Type: "blob"},
+				{Path: "openapi-generator.md", Type: "blob"},
+			},
+		},
+	}
 
-	for _, name := range fileNames {
-		graphqlData.Repository.Object.Tree.Entries = append(
-			graphqlData.Repository.Object.Tree.Entries,
-			struct {
-				Name string
-				Type string
-				Path string
-			}{Name: name, Type: "blob"},
-		)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			specs, docs := findInterfaceDocumentation(tt.entries)
+			if strings.Join(specs, ",") != strings.Join(tt.wantSpecs, ",") {
+				t.Errorf("findInterfaceDocumentation() specs = %v, want %v", specs, tt.wantSpecs)
+			}
+			if strings.Join(docs, ",") != strings.Join(tt.wantDocs, ",") {
+				t.Errorf("findInterfaceDocumentation() docs = %v, want %v", docs, tt.wantDocs)
+			}
+		})
+	}
+}
+
+func Test_findThreatModels(t *testing.T) {
+	entries := []data.RepoTreeEntry{
+		{Path: "docs", Type: "tree"},
+		{Path: "docs/THREAT_MODEL.md", Type: "blob"},
+		{Path: "security/threat-dragon/app.json", Type: "blob"},
+		{Path: "models/service.tm7", Type: "blob"},
+		{Path: "package.json", Type: "blob"},
+		{Path: "vendor/github.com/org/lib/threat-model.md", Type: "blob"},
+	}
+
+	got := findThreatModels(entries)
+	want := []string{"docs/THREAT_MODEL.md", "security/threat-dragon/app.json", "models/service.tm7"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("findThreatModels() = %v, want %v", got, want)
+	}
+}
+
+func Test_declaredAssessments(t *testing.T) {
+	tests := []struct {
+		name                string
+		posture             si.SecurityPosture
+		wantWithEvidence    []string
+		wantWithoutEvidence []string
+	}{
+		{
+			name: "no assessments",
+		},
+		{
+			name: "self assessment with evidence",
+			posture: func() si.SecurityPosture {
+				var posture si.SecurityPosture
+				posture.Assessments.Self = si.Assessment{
+					Name:     ptrTo("Threat model"),
+					Evidence: ptrTo(si.URL("https://example.com/threat-model")),
+				}
+				return posture
+			}(),
+			wantWithEvidence: []string{`self assessment "Threat model" at https://example.com/threat-model`},
+		},
+		{
+			name: "third-party assessments with and without evidence",
+			posture: func() si.SecurityPosture {
+				var posture si.SecurityPosture
+				posture.Assessments.ThirdPartyAssessment = []si.Assessment{
+					{Comment: "Audit by a security firm", Evidence: ptrTo(si.URL("https://example.com/audit.pdf"))},
+					{Comment: "Scheduled for next year"},
+				}
+				return posture
+			}(),
+			wantWithEvidence:    []string{"third-party assessment (Audit by a security firm) at https://example.com/audit.pdf"},
+			wantWithoutEvidence: []string{"third-party assessment (Scheduled for next year)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			insights := si.SecurityInsights{Repository: &si.Repository{SecurityPosture: tt.posture}}
+			withEvidence, withoutEvidence := declaredAssessments(insights)
+			if strings.Join(withEvidence, ",") != strings.Join(tt.wantWithEvidence, ",") {
+				t.Errorf("declaredAssessments() withEvidence = %v, want %v", withEvidence, tt.wantWithEvidence)
+			}
+			if strings.Join(withoutEvidence, ",") != strings.Join(tt.wantWithoutEvidence, ",") {
+				t.Errorf("declaredAssessments() withoutEvidence = %v, want %v", withoutEvidence, tt.wantWithoutEvidence)
+			}
+		})
+	}
+}
+
+func Test_HasThreatModel(t *testing.T) {
+	var posture si.SecurityPosture
+	posture.Assessments.Self = si.Assessment{Evidence: ptrTo(si.URL("https://example.com/THREAT_MODEL.md"))}
+	payload := data.Payload{
+		RestData: &data.RestData{
+			Insights: si.SecurityInsights{Repository: &si.Repository{SecurityPosture: posture}},
+		},
 	}
 
-	return graphqlData
+	gotResult, gotMsg, _ := HasThreatModel(payload)
+	if gotResult != gemara.Passed {
+		t.Errorf("HasThreatModel() result = %v, want %v", gotResult, gemara.Passed)
+	}
+	wantMsg := "Threat model was specified in Security Insights data: self assessment at https://example.com/THREAT_MODEL.md"
+	if gotMsg != wantMsg {
+		t.Errorf("HasThreatModel() message = %q, want %q", gotMsg, wantMsg)
+	}
+
+	gotResult, _, _ = HasSecurityAssessment(payload)
+	if gotResult != gemara.Passed {
+		t.Errorf("HasSecurityAssessment() result = %v, want %v", gotResult, gemara.Passed)
+	}
+}
+
+func Test_TreeStepsWithoutRestData(t *testing.T) {
+	steps := map[string]func(any) (gemara.Result, string, gemara.ConfidenceLevel){
+		"HasExternalInterfaceDocumentation": HasExternalInterfaceDocumentation,
+		"HasSecurityAssessment":             HasSecurityAssessment,
+		"HasThreatModel":                    HasThreatModel,
+	}
+	for name, step := range steps {
+		t.Run(name, func(t *testing.T) {
+			gotResult, gotMsg, _ := step(data.Payload{})
+			if gotResult != gemara.Unknown {
+				t.Errorf("%s() result = %v, want %v", name, gotResult, gemara.Unknown)
+			}
+			if wantMsg := "Repository data is not available in the payload"; gotMsg != wantMsg {
+				t.Errorf("%s() message = %q, want %q", name, gotMsg, wantMsg)
+			}
+		})
+	}
+}
+
+// treeWithFiles is a helper to create a repository tree with specified files
+func treeWithFiles(fileNames []string) (entries []data.RepoTreeEntry) {
+	for _, name := range fileNames {
+		entries = append(entries, data.RepoTreeEntry{Path: name, Type: "blob"})
+	}
+	return entries
 }
 
 // fileEntry represents a file or directory entry for testing
@@ -205,20 +412,10 @@ type fileEntry struct {
 	Type string // "blob" for file, "tree" for directory
 }
 
-// buildGraphqlDataWithEntries is a helper to create GraphqlRepoData with specified entries (files or directories)
-func buildGraphqlDataWithEntries(entries []fileEntry) *data.GraphqlRepoData {
-	graphqlData := &data.GraphqlRepoData{}
-
-	for _, entry := range entries {
-		graphqlData.Repository.Object.Tree.Entries = append(
-			graphqlData.Repository.Object.Tree.Entries,
-			struct {
-				Name string
-				Type string
-				Path string
-			}{Name: entry.Name, Type: entry.Type},
-		)
+// treeWithEntries is a helper to create a repository tree with specified entries (files or directories)
+func treeWithEntries(fileEntries []fileEntry) (entries []data.RepoTreeEntry) {
+	for _, entry := range fileEntries {
+		entries = append(entries, data.RepoTreeEntry{Path: entry.Name, Type: entry.Type})
 	}
-
-	return graphqlData
+	return entries
 }
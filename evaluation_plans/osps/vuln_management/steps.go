@@ -1,10 +1,21 @@
 package vuln_management
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"regexp"
 	"slices"
+	"strings"
 
 	"github.com/gemaraproj/go-gemara"
+	"github.com/ossf/si-tooling/v2/si"
+	"github.com/rhysd/actionlint"
 
+	"github.com/ossf/pvtr-github-repo-scanner/data"
 	"github.com/ossf/pvtr-github-repo-scanner/evaluation_plans/reusable_steps"
 )
 
@@ -83,3 +94,635 @@ func HasPrivateVulnerabilityReporting(payloadData any) (result gemara.Result, me
 
 	return gemara.Failed, "No private vulnerability reporting contact method found in Security Insights data", confidence
 }
+
+// DependencyScanningActions are actions and reusable workflows that check dependencies for known vulnerabilities
+var DependencyScanningActions = []string{
+	"actions/dependency-review-action",
+	"google/osv-scanner-action",
+	"snyk/actions",
+	"aquasecurity/trivy-action",
+	"anchore/scan-action",
+}
+
+// DependencyScanningCommands are CLI invocations that check dependencies for known vulnerabilities
+var DependencyScanningCommands = []string{"osv-scanner", "snyk test", "trivy fs", "trivy repo", "trivy image", "grype"}
+
+func DependencyScanningIsRequired(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	data, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	workflows, result, message := reusable_steps.ParseWorkflows(data)
+	if result == gemara.NotApplicable {
+		return gemara.Failed, fmt.Sprintf("No dependency vulnerability scanning found in workflows: %s", message), confidence
+	}
+	if message != "" {
+		return result, message, confidence
+	}
+
+	var scanningJobs []string
+	for _, workflow := range workflows {
+		scanningJobs = append(scanningJobs, findDependencyScanningJobs(workflow.Workflow)...)
+	}
+	if len(scanningJobs) == 0 {
+		return gemara.Failed, "No dependency vulnerability scanning found in workflows", confidence
+	}
+
+	// required checks come from the same rulesets and branch protection sources used for OSPS-QA-03.01
+	requiredChecks := slices.Clone(data.Repository.DefaultBranchRef.BranchProtectionRule.RequiredStatusCheckContexts)
+	rules, err := data.Rulesets(data.Repository.DefaultBranchRef.Name)
+	if err != nil {
+		data.Config.Logger.Error(err.Error())
+	}
+	for _, rule := range rules {
+		for _, requiredCheck := range rule.Parameters.RequiredChecks {
+			requiredChecks = append(requiredChecks, requiredCheck.Context)
+		}
+	}
+
+	var requiredJobs []string
+	for _, job := range scanningJobs {
+		if checkIsRequired(job, requiredChecks) {
+			requiredJobs = append(requiredJobs, job)
+		}
+	}
+	if len(requiredJobs) > 0 {
+		return gemara.Passed, fmt.Sprintf("Dependency vulnerability scanning is a required status check: %s", strings.Join(requiredJobs, ", ")), confidence
+	}
+	return gemara.NeedsReview, fmt.Sprintf("Dependency vulnerability scanning is configured but not a required status check: %s", strings.Join(scanningJobs, ", ")), confidence
+}
+
+// findDependencyScanningJobs returns the status check names of jobs that scan dependencies for vulnerabilities
+func findDependencyScanningJobs(workflow *actionlint.Workflow) (checkNames []string) {
+	for _, id := range slices.Sorted(maps.Keys(workflow.Jobs)) {
+		job := workflow.Jobs[id]
+		if job == nil || !jobScansDependencies(job) {
+			continue
+		}
+		// a job's status check is reported under its name, falling back to its ID
+		checkName := id
+		if job.Name != nil && job.Name.Value != "" {
+			checkName = job.Name.Value
+		} else if job.ID != nil {
+			checkName = job.ID.Value
+		}
+		checkNames = append(checkNames, checkName)
+	}
+	return checkNames
+}
+
+func jobScansDependencies(job *actionlint.Job) bool {
+	if job.WorkflowCall != nil && job.WorkflowCall.Uses != nil && usesScanningAction(job.WorkflowCall.Uses.Value) {
+		return true
+	}
+	for _, step := range job.Steps {
+		if step == nil {
+			continue
+		}
+		switch exec := step.Exec.(type) {
+		case *actionlint.ExecAction:
+			if exec.Uses != nil && usesScanningAction(exec.Uses.Value) {
+				return true
+			}
+		case *actionlint.ExecRun:
+			if exec.Run == nil {
+				continue
+			}
+			for _, command := range DependencyScanningCommands {
+				if strings.Contains(exec.Run.Value, command) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func usesScanningAction(uses string) bool {
+	uses = strings.ToLower(uses)
+	for _, action := range DependencyScanningActions {
+		if strings.HasPrefix(uses, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIsRequired matches a job's check name against required contexts, including the
+// "caller / called" names GitHub reports for jobs in reusable workflows
+func checkIsRequired(checkName string, requiredChecks []string) bool {
+	for _, requiredCheck := range requiredChecks {
+		if requiredCheck == checkName ||
+			strings.HasPrefix(requiredCheck, checkName+" / ") ||
+			strings.HasSuffix(requiredCheck, " / "+checkName) {
+			return true
+		}
+	}
+	return false
+}
+
+// VexDirectories are repository directories conventionally used to publish VEX documents
+var VexDirectories = []string{"vex", ".vex", ".openvex"}
+
+// vexCandidate is a possible VEX document and a way to retrieve it
+type vexCandidate struct {
+	location string
+	fetch    func() ([]byte, error)
+}
+
+// vexDocument holds the fields needed to recognize OpenVEX, CSAF VEX and CycloneDX VEX documents
+type vexDocument struct {
+	// OpenVEX
+	Context    string `json:"@context"`
+	Statements []struct {
+		Vulnerability json.RawMessage `json:"vulnerability"`
+		Status        string          `json:"status"`
+		Products      json.RawMessage `json:"products"`
+	} `json:"statements"`
+	// CSAF
+	Document *struct {
+		Category string `json:"category"`
+	} `json:"document"`
+	ProductTree json.RawMessage `json:"product_tree"`
+	// CycloneDX
+	BomFormat string `json:"bomFormat"`
+	Metadata  struct {
+		Component json.RawMessage `json:"component"`
+	} `json:"metadata"`
+	// CSAF and CycloneDX
+	Vulnerabilities []json.RawMessage `json:"vulnerabilities"`
+}
+
+func HasVexDocuments(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	data, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	candidates := findVexCandidates(data)
+	if len(candidates) == 0 {
+		return gemara.Failed, "No VEX documents were found in the repository, release assets, or Security Insights data", confidence
+	}
+
+	var projectIDs []string
+	if data.Config != nil {
+		projectIDs = vexProjectIdentifiers(data.Config.GetString("owner"), data.Config.GetString("repo"))
+	}
+	var valid, invalid []string
+	for _, candidate := range candidates {
+		content, err := candidate.fetch()
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s (could not be retrieved: %s)", candidate.location, err.Error()))
+			continue
+		}
+		format, problem := validateVexDocument(content, projectIDs)
+		if problem != "" {
+			invalid = append(invalid, fmt.Sprintf("%s (%s)", candidate.location, problem))
+			continue
+		}
+		valid = append(valid, fmt.Sprintf("%s (%s)", candidate.location, format))
+	}
+
+	if len(valid) > 0 {
+		return gemara.Passed, fmt.Sprintf("VEX documents referencing this project were found: %s", strings.Join(valid, ", ")), confidence
+	}
+	return gemara.NeedsReview, fmt.Sprintf("Possible VEX documents were found but could not be validated: %s", strings.Join(invalid, "; ")), confidence
+}
+
+func isVexFileName(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".json") && strings.Contains(name, "vex")
+}
+
+func isVexAttestation(name string, predicateURI string) bool {
+	return strings.Contains(strings.ToLower(name), "vex") || strings.Contains(strings.ToLower(predicateURI), "openvex")
+}
+
+// inVexDirectory reports whether a JSON file is anywhere below one of the VexDirectories
+func inVexDirectory(filePath string) bool {
+	filePath = strings.ToLower(filePath)
+	if !strings.HasSuffix(filePath, ".json") {
+		return false
+	}
+	for _, dir := range strings.Split(path.Dir(filePath), "/") {
+		if slices.Contains(VexDirectories, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// repoFileCandidate returns a candidate that reads a file from the repository
+func repoFileCandidate(payload data.Payload, filePath string) vexCandidate {
+	return vexCandidate{
+		location: filePath,
+		fetch: func() ([]byte, error) {
+			file, err := payload.GetFileContent(filePath)
+			if err != nil {
+				return nil, err
+			}
+			content, err := file.GetContent()
+			return []byte(content), err
+		},
+	}
+}
+
+// insightsVexLinks returns the Security Insights links to vulnerability reporting, advisory and assessment
+// documents whose file name marks them as VEX or CSAF data
+func insightsVexLinks(insights si.SecurityInsights) (links []string) {
+	var urls []*si.URL
+	if insights.Project != nil {
+		urls = append(urls, insights.Project.VulnerabilityReporting.Policy, insights.Project.VulnerabilityReporting.SecurityPolicy)
+	}
+	if insights.Repository != nil {
+		if insights.Repository.Documentation != nil {
+			urls = append(urls, insights.Repository.Documentation.SecurityPolicy)
+		}
+		assessments := insights.Repository.SecurityPosture.Assessments
+		urls = append(urls, assessments.Self.Evidence)
+		for _, assessment := range assessments.ThirdPartyAssessment {
+			urls = append(urls, assessment.Evidence)
+		}
+	}
+	for _, link := range urls {
+		if link == nil {
+			continue
+		}
+		name := strings.ToLower(path.Base(string(*link)))
+		if isVexFileName(name) || (strings.HasSuffix(name, ".json") && strings.Contains(name, "csaf")) {
+			links = append(links, string(*link))
+		}
+	}
+	return links
+}
+
+// findVexCandidates looks for VEX documents in the repository tree and VEX directories, the latest
+// release's assets, and the attestations and vulnerability links declared in Security Insights
+func findVexCandidates(payload data.Payload) (candidates []vexCandidate) {
+	seen := map[string]bool{}
+	add := func(candidate vexCandidate) {
+		if !seen[candidate.location] {
+			seen[candidate.location] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	if payload.RestData != nil {
+		entries, err := payload.Tree()
+		if err != nil {
+			payload.Config.Logger.Trace(fmt.Sprintf("unexpected response while listing the repository tree: %s", err.Error()))
+		}
+		for _, entry := range entries {
+			if entry.Type == "blob" && (isVexFileName(path.Base(entry.Path)) || inVexDirectory(entry.Path)) {
+				add(repoFileCandidate(payload, entry.Path))
+			}
+		}
+	}
+
+	releases, err := payload.Releases()
+	if err != nil {
+		payload.Config.Logger.Trace(fmt.Sprintf("unexpected response while listing releases: %s", err.Error()))
+	}
+	if len(releases) > 0 {
+		latest := releases[0]
+		for _, asset := range latest.Assets {
+			if !isVexFileName(asset.Name) || asset.DownloadURL == "" {
+				continue
+			}
+			add(vexCandidate{
+				location: fmt.Sprintf("release %s asset %s", latest.TagName, asset.Name),
+				fetch: func() ([]byte, error) {
+					return payload.MakeApiCall(asset.DownloadURL, false)
+				},
+			})
+		}
+	}
+
+	var attestations []si.Attestation
+	if payload.Insights.Repository != nil {
+		if payload.Insights.Repository.ReleaseDetails != nil {
+			attestations = append(attestations, payload.Insights.Repository.ReleaseDetails.Attestations...)
+		}
+		for _, tool := range payload.Insights.Repository.SecurityPosture.Tools {
+			for _, attestation := range []*si.Attestation{tool.Results.Adhoc, tool.Results.CI, tool.Results.Release} {
+				if attestation != nil {
+					attestations = append(attestations, *attestation)
+				}
+			}
+		}
+	}
+	var links []string
+	for _, attestation := range attestations {
+		if !isVexAttestation(attestation.Name, attestation.PredicateURI) || attestation.Location == "" {
+			continue
+		}
+		links = append(links, string(attestation.Location))
+	}
+	links = append(links, insightsVexLinks(payload.Insights)...)
+	for _, link := range links {
+		if filePath := payload.RepoFilePath(link); filePath != "" {
+			add(repoFileCandidate(payload, filePath))
+			continue
+		}
+		add(vexCandidate{
+			location: link,
+			fetch: func() ([]byte, error) {
+				return payload.MakeApiCall(link, false)
+			},
+		})
+	}
+	return candidates
+}
+
+// vexProjectIdentifiers returns the forms in which a VEX document names this repository as a product:
+// owner/repo, which package URLs such as pkg:github/owner/repo and pkg:golang/github.com/owner/repo
+// contain, and the owner:repo vendor and product of a CPE
+func vexProjectIdentifiers(owner, repo string) []string {
+	if owner == "" || repo == "" {
+		return nil
+	}
+	owner, repo = strings.ToLower(owner), strings.ToLower(repo)
+	return []string{owner + "/" + repo, "cpe:2.3:a:" + owner + ":" + repo, "cpe:/a:" + owner + ":" + repo}
+}
+
+// referencesProject reports whether product contains projectID as a whole name,
+// so that org/tool is found in pkg:github/org/tool@v1.0.0 but not in org/tool-extras or myorg/tool
+func referencesProject(product []byte, projectID string) bool {
+	product = bytes.ToLower(product)
+	id := []byte(projectID)
+	for offset := 0; ; {
+		index := bytes.Index(product[offset:], id)
+		if index < 0 {
+			return false
+		}
+		start := offset + index
+		end := start + len(id)
+		if (start == 0 || !isNameChar(product[start-1])) && (end == len(product) || !isNameChar(product[end])) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z')
+}
+
+// validateVexDocument identifies the VEX format of the content and checks that it is well-formed
+// and names one of the project identifiers among its products
+func validateVexDocument(content []byte, projectIDs []string) (format string, problem string) {
+	var document vexDocument
+	if err := json.Unmarshal(content, &document); err != nil {
+		return "", fmt.Sprintf("not valid JSON: %s", err.Error())
+	}
+
+	var products []json.RawMessage
+	switch {
+	case strings.HasPrefix(document.Context, "https://openvex.dev/ns"):
+		format = "OpenVEX"
+		if len(document.Statements) == 0 {
+			return format, "OpenVEX document has no statements"
+		}
+		for _, statement := range document.Statements {
+			if statement.Status == "" || len(statement.Vulnerability) == 0 {
+				return format, "OpenVEX statement is missing a vulnerability or status"
+			}
+			products = append(products, statement.Products)
+		}
+	case document.Document != nil && document.Document.Category == "csaf_vex":
+		format = "CSAF VEX"
+		if len(document.ProductTree) == 0 || len(document.Vulnerabilities) == 0 {
+			return format, "CSAF VEX document is missing a product tree or vulnerabilities"
+		}
+		products = append(products, document.ProductTree)
+	case document.BomFormat == "CycloneDX":
+		format = "CycloneDX VEX"
+		if len(document.Vulnerabilities) == 0 {
+			return format, "CycloneDX document has no vulnerabilities"
+		}
+		products = append(products, document.Metadata.Component)
+		products = append(products, document.Vulnerabilities...)
+	default:
+		return "", "not a recognized OpenVEX, CSAF VEX or CycloneDX VEX document"
+	}
+
+	for _, product := range products {
+		for _, projectID := range projectIDs {
+			if referencesProject(product, projectID) {
+				return format, ""
+			}
+		}
+	}
+	return format, fmt.Sprintf("%s document does not reference this project's products", format)
+}
+
+// advisoryListingPath matches a repository's list of published GitHub Security Advisories, but not the
+// form for reporting a new vulnerability or any other page below it
+var advisoryListingPath = regexp.MustCompile(`^/[^/]+/[^/]+/security/advisories/?$`)
+
+// AdvisoryFeedSuffixes are path endings of RSS, Atom and CSAF feeds of vulnerability data
+var AdvisoryFeedSuffixes = []string{".rss", ".atom", "/feed", "/feed.xml", "/rss.xml", "/atom.xml", "/provider-metadata.json"}
+
+// isAdvisoryFeedURL reports whether link has the shape of published vulnerability data: a GitHub Security
+// Advisory listing, OSV, or an RSS, Atom or CSAF feed. Reporting forms and policy documents do not qualify.
+func isAdvisoryFeedURL(link string) bool {
+	parsed, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	urlPath := strings.ToLower(parsed.Path)
+	switch {
+	case advisoryListingPath.MatchString(urlPath):
+		return true
+	case host == "osv.dev" || strings.HasSuffix(host, ".osv.dev"):
+		return true
+	}
+	for _, suffix := range AdvisoryFeedSuffixes {
+		if strings.HasSuffix(urlPath, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func PublishesVulnerabilityData(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	data, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	advisories, err := data.SecurityAdvisories()
+	if len(advisories) > 0 {
+		return gemara.Passed, fmt.Sprintf("Found %d published GitHub Security Advisories", len(advisories)), confidence
+	}
+
+	if feed := insightsAdvisoryFeed(data.Insights); feed != "" {
+		return gemara.Passed, fmt.Sprintf("Security Insights data references an advisory feed: %s", feed), confidence
+	}
+
+	// an advisory listing that could not be fetched says nothing about whether any were published
+	if err != nil {
+		return gemara.Unknown, fmt.Sprintf("Failed to list GitHub Security Advisories: %s", err.Error()), confidence
+	}
+
+	// having no published vulnerabilities yet is legitimate, so this cannot fail automatically
+	return gemara.NeedsReview, "No published GitHub Security Advisories or advisory feed in Security Insights data were found; review whether any vulnerabilities have been reported", confidence
+}
+
+// insightsAdvisoryFeed returns the first Security Insights URL that is a feed of published vulnerability data
+func insightsAdvisoryFeed(insights si.SecurityInsights) string {
+	var links []string
+	if insights.Project != nil {
+		reporting := insights.Project.VulnerabilityReporting
+		for _, link := range []*si.URL{reporting.Policy, reporting.SecurityPolicy} {
+			if link != nil {
+				links = append(links, string(*link))
+			}
+		}
+	}
+	if insights.Repository != nil {
+		if insights.Repository.ReleaseDetails != nil {
+			for _, attestation := range insights.Repository.ReleaseDetails.Attestations {
+				links = append(links, string(attestation.Location))
+			}
+		}
+		if insights.Repository.Documentation != nil && insights.Repository.Documentation.SecurityPolicy != nil {
+			links = append(links, string(*insights.Repository.Documentation.SecurityPolicy))
+		}
+	}
+
+	for _, link := range links {
+		if isAdvisoryFeedURL(link) {
+			return link
+		}
+	}
+	return ""
+}
+
+// scaPolicyMatch is the policy text that satisfies an SCA policy requirement
+type scaPolicyMatch struct {
+	path     string
+	heading  string
+	sentence string
+}
+
+func (m scaPolicyMatch) String() string {
+	if m.heading == "" {
+		return fmt.Sprintf("%s: %q", m.path, m.sentence)
+	}
+	return fmt.Sprintf("%s (%s): %q", m.path, m.heading, m.sentence)
+}
+
+var (
+	sentenceSeparatorRegex = regexp.MustCompile(`[.!?](\s+|$)|\n`)
+	scaFindingRegex        = regexp.MustCompile(`(?i)vulnerab|violation|finding|\bcves?\b|advisor|dependenc|\bsca\b|licen[cs]e`)
+	severityRegex          = regexp.MustCompile(`(?i)\b(critical|high|medium|moderate|low|severity|cvss)\b`)
+	remediationWindowRegex = regexp.MustCompile(`(?i)\b(\d+\s*(business\s+|working\s+|calendar\s+)?(hours?|days?|weeks?|months?)|sla|service level|immediately)\b`)
+	beforeReleaseRegex     = regexp.MustCompile(`(?i)\b(before|prior to|ahead of)\b.{0,60}\b(releas|publish|ship|tag)|\bblock\w*\b.{0,40}\breleas|\breleas\w*\b.{0,20}\bblocked\b|\b(no|not|never)\b.{0,40}\breleas\w*\b.{0,60}\b(known|unresolved|open|outstanding|unaddressed)\b`)
+)
+
+func HasScaRemediationThreshold(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	payload, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	documents, externalPolicy := scaPolicyDocuments(payload)
+	if match, found := findScaPolicyText(documents, isRemediationThreshold); found {
+		return gemara.Passed, fmt.Sprintf("Remediation threshold for SCA findings found in %s", match), confidence
+	}
+	return scaPolicyNotFound("remediation threshold for SCA findings", documents, externalPolicy, confidence)
+}
+
+func HasScaPreReleasePolicy(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+	payload, message := reusable_steps.VerifyPayload(payloadData)
+	if message != "" {
+		return gemara.Unknown, message, confidence
+	}
+
+	documents, externalPolicy := scaPolicyDocuments(payload)
+	if match, found := findScaPolicyText(documents, isPreReleaseRule); found {
+		return gemara.Passed, fmt.Sprintf("Policy to address SCA violations before release found in %s", match), confidence
+	}
+	return scaPolicyNotFound("policy to address SCA violations before release", documents, externalPolicy, confidence)
+}
+
+func scaPolicyNotFound(requirement string, documents []data.MarkdownDocument, externalPolicy string, confidence gemara.ConfidenceLevel) (gemara.Result, string, gemara.ConfidenceLevel) {
+	if externalPolicy != "" {
+		return gemara.NeedsReview, fmt.Sprintf("No %s was found in the repository, but a dependency management policy is specified in Security Insights data (%s) - manual review needed", requirement, externalPolicy), confidence
+	}
+	if len(documents) == 0 {
+		return gemara.Failed, "No dependency management policy was specified in Security Insights data and no SECURITY.md was found", confidence
+	}
+	var paths []string
+	for _, document := range documents {
+		paths = append(paths, document.Path)
+	}
+	return gemara.Failed, fmt.Sprintf("No %s was found in %s", requirement, strings.Join(paths, ", ")), confidence
+}
+
+// scaPolicyDocuments parses the dependency management policy when it is a markdown file in this repository, and SECURITY.md.
+// A policy that lives outside the repository is returned as a link for manual review.
+func scaPolicyDocuments(payload data.Payload) (documents []data.MarkdownDocument, externalPolicy string) {
+	if link := payload.Insights.Repository.Documentation.DependencyManagementPolicy; link != nil {
+		policyPath := payload.RepoFilePath(string(*link))
+		if strings.HasSuffix(strings.ToLower(policyPath), ".md") {
+			document, err := payload.GetMarkdownDocumentByPath(policyPath)
+			if err != nil {
+				payload.Config.Logger.Error(fmt.Sprintf("failed to read dependency management policy %s: %s", policyPath, err.Error()))
+			} else {
+				documents = append(documents, document)
+			}
+		}
+		if len(documents) == 0 {
+			externalPolicy = string(*link)
+		}
+	}
+
+	document, err := payload.GetMarkdownDocument("security.md")
+	if err != nil {
+		payload.Config.Logger.Error(fmt.Sprintf("failed to read security.md: %s", err.Error()))
+	} else if document.Path != "" && !slices.ContainsFunc(documents, func(d data.MarkdownDocument) bool { return d.Path == document.Path }) {
+		documents = append(documents, document)
+	}
+	return documents, externalPolicy
+}
+
+// findScaPolicyText returns the first sentence that satisfies matches, citing the innermost section that contains it
+func findScaPolicyText(documents []data.MarkdownDocument, matches func(sentence string) bool) (match scaPolicyMatch, found bool) {
+	for _, document := range documents {
+		sectionLength := 0
+		for _, section := range document.Sections {
+			for _, sentence := range sentenceSeparatorRegex.Split(section.Body, -1) {
+				sentence = strings.TrimSpace(sentence)
+				if sentence == "" || !matches(sentence) {
+					continue
+				}
+				if found && match.sentence == sentence && len(section.Body) < sectionLength {
+					// a nested section holds the same sentence more precisely
+					match.heading = section.Heading
+					sectionLength = len(section.Body)
+				}
+				if !found {
+					match = scaPolicyMatch{path: document.Path, heading: section.Heading, sentence: sentence}
+					sectionLength = len(section.Body)
+					found = true
+				}
+			}
+		}
+		if found {
+			return match, true
+		}
+	}
+	return match, false
+}
+
+func isRemediationThreshold(sentence string) bool {
+	return scaFindingRegex.MatchString(sentence) && severityRegex.MatchString(sentence) && remediationWindowRegex.MatchString(sentence)
+}
+
+func isPreReleaseRule(sentence string) bool {
+	return scaFindingRegex.MatchString(sentence) && beforeReleaseRegex.MatchString(sentence)
+}
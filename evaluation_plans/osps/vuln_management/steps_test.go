@@ -1,11 +1,14 @@
 package vuln_management
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/gemaraproj/go-gemara"
+	"github.com/privateerproj/privateer-sdk/config"
 	"github.com/ossf/si-tooling/v2/si"
 	"github.com/ossf/pvtr-github-repo-scanner/data"
+	"github.com/rhysd/actionlint"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -281,3 +284,458 @@ func TestHasPrivateVulnerabilityReporting(t *testing.T) {
 		})
 	}
 }
+
+func TestFindDependencyScanningJobs(t *testing.T) {
+	tests := []struct {
+		name         string
+		workflowFile string
+		expected     []string
+	}{
+		{
+			name: "dependency review action uses the job name",
+			workflowFile: `on: pull_request
+jobs:
+  review:
+    name: Dependency Review
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/dependency-review-action@v4`,
+			expected: []string{"Dependency Review"},
+		},
+		{
+			name: "osv-scanner command uses the job id",
+			workflowFile: `on: pull_request
+jobs:
+  osv:
+    runs-on: ubuntu-latest
+    steps:
+      - run: osv-scanner scan --recursive .`,
+			expected: []string{"osv"},
+		},
+		{
+			name: "reusable osv-scanner workflow",
+			workflowFile: `on: pull_request
+jobs:
+  scan:
+    uses: google/osv-scanner-action/.github/workflows/osv-scanner-reusable-pr.yml@v2`,
+			expected: []string{"scan"},
+		},
+		{
+			name: "jobs without scanners",
+			workflowFile: `on: pull_request
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v5
+      - run: go test ./...`,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workflow, errs := actionlint.Parse([]byte(tt.workflowFile))
+			assert.Empty(t, errs)
+			assert.Equal(t, tt.expected, findDependencyScanningJobs(workflow))
+		})
+	}
+}
+
+func TestCheckIsRequired(t *testing.T) {
+	tests := []struct {
+		name           string
+		checkName      string
+		requiredChecks []string
+		expected       bool
+	}{
+		{"exact match", "Dependency Review", []string{"build", "Dependency Review"}, true},
+		{"reusable workflow job", "scan", []string{"scan / osv-scan"}, true},
+		{"prefixed by workflow name", "osv", []string{"OSV / osv"}, true},
+		{"not required", "osv", []string{"build", "test"}, false},
+		{"no required checks", "osv", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, checkIsRequired(tt.checkName, tt.requiredChecks))
+		})
+	}
+}
+
+func TestValidateVexDocument(t *testing.T) {
+	projectIDs := vexProjectIdentifiers("org", "tool")
+	tests := []struct {
+		name          string
+		content       string
+		expectedFmt   string
+		expectProblem bool
+	}{
+		{
+			name:        "OpenVEX document for this project",
+			content:     `{"@context": "https://openvex.dev/ns/v0.2.0", "statements": [{"vulnerability": {"name": "CVE-2024-0001"}, "products": [{"@id": "pkg:github/org/tool@v1.0.0"}], "status": "not_affected"}]}`,
+			expectedFmt: "OpenVEX",
+		},
+		{
+			name:          "OpenVEX document for another project",
+			content:       `{"@context": "https://openvex.dev/ns/v0.2.0", "statements": [{"vulnerability": {"name": "CVE-2024-0001"}, "products": [{"@id": "pkg:github/other/thing@v1.0.0"}], "status": "not_affected"}]}`,
+			expectedFmt:   "OpenVEX",
+			expectProblem: true,
+		},
+		{
+			name:          "OpenVEX statement without status",
+			content:       `{"@context": "https://openvex.dev/ns/v0.2.0", "statements": [{"vulnerability": {"name": "CVE-2024-0001"}, "products": [{"@id": "pkg:github/org/tool"}]}]}`,
+			expectedFmt:   "OpenVEX",
+			expectProblem: true,
+		},
+		{
+			name:        "CSAF VEX document",
+			content:     `{"document": {"category": "csaf_vex"}, "product_tree": {"full_product_names": [{"name": "tool 1.0", "product_id": "tool-1.0", "product_identification_helper": {"cpe": "cpe:2.3:a:org:tool:1.0:*:*:*:*:*:*:*"}}]}, "vulnerabilities": [{"cve": "CVE-2024-0001"}]}`,
+			expectedFmt: "CSAF VEX",
+		},
+		{
+			name:          "product named only by the bare repository name",
+			content:       `{"document": {"category": "csaf_vex"}, "product_tree": {"full_product_names": [{"name": "tool 1.0", "product_id": "tool-1.0"}]}, "vulnerabilities": [{"cve": "CVE-2024-0001"}]}`,
+			expectedFmt:   "CSAF VEX",
+			expectProblem: true,
+		},
+		{
+			name:          "product whose name extends this repository",
+			content:       `{"@context": "https://openvex.dev/ns/v0.2.0", "statements": [{"vulnerability": {"name": "CVE-2024-0001"}, "products": [{"@id": "pkg:github/org/tool-extras@v1.0.0"}], "status": "not_affected"}]}`,
+			expectedFmt:   "OpenVEX",
+			expectProblem: true,
+		},
+		{
+			name:        "CycloneDX VEX document",
+			content:     `{"bomFormat": "CycloneDX", "specVersion": "1.5", "metadata": {"component": {"name": "tool", "purl": "pkg:golang/github.com/org/tool"}}, "vulnerabilities": [{"id": "CVE-2024-0001", "analysis": {"state": "not_affected"}}]}`,
+			expectedFmt: "CycloneDX VEX",
+		},
+		{
+			name:          "CycloneDX SBOM without vulnerabilities",
+			content:       `{"bomFormat": "CycloneDX", "metadata": {"component": {"name": "tool"}}}`,
+			expectedFmt:   "CycloneDX VEX",
+			expectProblem: true,
+		},
+		{
+			name:          "unrecognized JSON",
+			content:       `{"hello": "world"}`,
+			expectProblem: true,
+		},
+		{
+			name:          "malformed JSON",
+			content:       `{"@context": `,
+			expectProblem: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, problem := validateVexDocument([]byte(tt.content), projectIDs)
+			assert.Equal(t, tt.expectedFmt, format)
+			assert.Equal(t, tt.expectProblem, problem != "", problem)
+		})
+	}
+}
+
+func TestInVexDirectory(t *testing.T) {
+	tests := []struct {
+		filePath string
+		expected bool
+	}{
+		{"vex/CVE-2024-0001.json", true},
+		{"security/.openvex/statements.json", true},
+		{"security/vex/README.md", false},
+		{"vexillology/flags.json", false},
+		{"CVE-2024-0001.json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filePath, func(t *testing.T) {
+			assert.Equal(t, tt.expected, inVexDirectory(tt.filePath))
+		})
+	}
+}
+
+func TestHasVexDocuments(t *testing.T) {
+	openVex := []byte(`{"@context": "https://openvex.dev/ns/v0.2.0", "statements": [{"vulnerability": {"name": "CVE-2024-0001"}, "products": [{"@id": "pkg:github/org/tool@v1.0.0"}], "status": "not_affected"}]}`)
+	tests := []struct {
+		name            string
+		releases        []data.ReleaseData
+		attestations    []si.Attestation
+		evidence        si.URL
+		apiResponse     []byte
+		expectedResult  gemara.Result
+		expectedMessage string
+	}{
+		{
+			name:            "No VEX documents",
+			releases:        []data.ReleaseData{{TagName: "v1.0.0", Assets: []data.ReleaseAsset{{Name: "tool.tar.gz"}}}},
+			expectedResult:  gemara.Failed,
+			expectedMessage: "No VEX documents were found in the repository, release assets, or Security Insights data",
+		},
+		{
+			name: "VEX document in release assets",
+			releases: []data.ReleaseData{{TagName: "v1.0.0", Assets: []data.ReleaseAsset{
+				{Name: "tool.openvex.json", DownloadURL: "https://example.com/tool.openvex.json"},
+			}}},
+			apiResponse:     openVex,
+			expectedResult:  gemara.Passed,
+			expectedMessage: "VEX documents referencing this project were found: release v1.0.0 asset tool.openvex.json (OpenVEX)",
+		},
+		{
+			name: "VEX document declared in Security Insights",
+			attestations: []si.Attestation{
+				{Name: "VEX", Location: "https://example.com/vex.json", PredicateURI: "https://openvex.dev/ns"},
+			},
+			apiResponse:     openVex,
+			expectedResult:  gemara.Passed,
+			expectedMessage: "VEX documents referencing this project were found: https://example.com/vex.json (OpenVEX)",
+		},
+		{
+			name:            "VEX document linked as assessment evidence in Security Insights",
+			evidence:        "https://example.com/advisories/tool.vex.json",
+			apiResponse:     openVex,
+			expectedResult:  gemara.Passed,
+			expectedMessage: "VEX documents referencing this project were found: https://example.com/advisories/tool.vex.json (OpenVEX)",
+		},
+		{
+			name: "VEX document that cannot be validated",
+			releases: []data.ReleaseData{{TagName: "v1.0.0", Assets: []data.ReleaseAsset{
+				{Name: "vex.json", DownloadURL: "https://example.com/vex.json"},
+			}}},
+			apiResponse:     []byte(`{"hello": "world"}`),
+			expectedResult:  gemara.NeedsReview,
+			expectedMessage: "Possible VEX documents were found but could not be validated: release v1.0.0 asset vex.json (not a recognized OpenVEX, CSAF VEX or CycloneDX VEX document)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := data.NewPayloadWithHTTPMock(data.Payload{
+				Config:   &config.Config{Vars: map[string]any{"owner": "org", "repo": "tool"}},
+				RestData: &data.RestData{},
+			}, tt.apiResponse, 200, nil)
+			payload = data.NewPayloadWithTree(payload, nil)
+			payload = data.NewPayloadWithReleases(payload, tt.releases)
+			payload.Insights.Repository.ReleaseDetails.Attestations = tt.attestations
+			if tt.evidence != "" {
+				payload.Insights.Repository.SecurityPosture.Assessments.Self.Evidence = &tt.evidence
+			}
+
+			result, message, _ := HasVexDocuments(payload)
+			assert.Equal(t, tt.expectedResult, result)
+			assert.Equal(t, tt.expectedMessage, message)
+		})
+	}
+}
+
+func TestPublishesVulnerabilityData(t *testing.T) {
+	tests := []struct {
+		name            string
+		payloadData     data.Payload
+		advisories      []data.SecurityAdvisory
+		advisoriesErr   error
+		expectedResult  gemara.Result
+		expectedMessage string
+	}{
+		{
+			name: "Published advisories",
+			payloadData: data.Payload{
+				RestData: &data.RestData{},
+			},
+			advisories:      []data.SecurityAdvisory{{GhsaId: "GHSA-xxxx-yyyy-zzzz"}, {GhsaId: "GHSA-aaaa-bbbb-cccc"}},
+			expectedResult:  gemara.Passed,
+			expectedMessage: "Found 2 published GitHub Security Advisories",
+		},
+		{
+			name: "Advisory feed in Security Insights",
+			payloadData: data.Payload{
+				RestData: &data.RestData{
+					Insights: si.SecurityInsights{
+						Project: &si.Project{
+							VulnerabilityReporting: si.VulnerabilityReporting{
+								Policy: ptrTo(si.URL("https://github.com/org/repo/security/advisories")),
+							},
+						},
+					},
+				},
+			},
+			expectedResult:  gemara.Passed,
+			expectedMessage: "Security Insights data references an advisory feed: https://github.com/org/repo/security/advisories",
+		},
+		{
+			name: "Reporting form is not an advisory feed",
+			payloadData: data.Payload{
+				RestData: &data.RestData{
+					Insights: si.SecurityInsights{
+						Project: &si.Project{
+							VulnerabilityReporting: si.VulnerabilityReporting{
+								Policy: ptrTo(si.URL("https://github.com/org/repo/security/advisories/new")),
+							},
+						},
+					},
+				},
+			},
+			expectedResult:  gemara.NeedsReview,
+			expectedMessage: "No published GitHub Security Advisories or advisory feed in Security Insights data were found; review whether any vulnerabilities have been reported",
+		},
+		{
+			name: "No advisories and no feed",
+			payloadData: data.Payload{
+				RestData: &data.RestData{
+					Insights: si.SecurityInsights{
+						Project: &si.Project{
+							VulnerabilityReporting: si.VulnerabilityReporting{
+								Policy: ptrTo(si.URL("https://github.com/org/repo/blob/main/SECURITY.md")),
+							},
+						},
+					},
+				},
+			},
+			expectedResult:  gemara.NeedsReview,
+			expectedMessage: "No published GitHub Security Advisories or advisory feed in Security Insights data were found; review whether any vulnerabilities have been reported",
+		},
+		{
+			name: "Advisories could not be listed",
+			payloadData: data.Payload{
+				RestData: &data.RestData{},
+			},
+			advisoriesErr:   errors.New("403 API rate limit exceeded"),
+			expectedResult:  gemara.Unknown,
+			expectedMessage: "Failed to list GitHub Security Advisories: 403 API rate limit exceeded",
+		},
+		{
+			name: "Advisory feed when advisories could not be listed",
+			payloadData: data.Payload{
+				RestData: &data.RestData{
+					Insights: si.SecurityInsights{
+						Project: &si.Project{
+							VulnerabilityReporting: si.VulnerabilityReporting{
+								Policy: ptrTo(si.URL("https://github.com/org/repo/security/advisories")),
+							},
+						},
+					},
+				},
+			},
+			advisoriesErr:   errors.New("403 API rate limit exceeded"),
+			expectedResult:  gemara.Passed,
+			expectedMessage: "Security Insights data references an advisory feed: https://github.com/org/repo/security/advisories",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := data.NewPayloadWithSecurityAdvisories(tt.payloadData, tt.advisories)
+			if tt.advisoriesErr != nil {
+				payload = data.NewPayloadWithSecurityAdvisoriesError(tt.payloadData, tt.advisoriesErr)
+			}
+			result, message, _ := PublishesVulnerabilityData(payload)
+			assert.Equal(t, tt.expectedResult, result)
+			assert.Equal(t, tt.expectedMessage, message)
+		})
+	}
+}
+
+func TestFindScaPolicyText(t *testing.T) {
+	security := data.ParseMarkdownDocument("SECURITY.md", []byte(`# Security Policy
+
+Please report vulnerabilities privately.
+
+## Dependency Vulnerabilities
+
+We scan dependencies on every pull request.
+
+### Remediation
+
+Critical and high severity vulnerabilities in dependencies are fixed within 14 days. Releases are blocked while known vulnerabilities in dependencies are unresolved.
+`))
+	dependencyPolicy := data.ParseMarkdownDocument("docs/dependencies.md", []byte(`Dependency license violations must be resolved before the next release.`))
+
+	tests := []struct {
+		name      string
+		documents []data.MarkdownDocument
+		matches   func(string) bool
+		wantFound bool
+		wantMatch string
+	}{
+		{
+			name:      "remediation threshold in nested section",
+			documents: []data.MarkdownDocument{security},
+			matches:   isRemediationThreshold,
+			wantFound: true,
+			wantMatch: `SECURITY.md (Remediation): "Critical and high severity vulnerabilities in dependencies are fixed within 14 days"`,
+		},
+		{
+			name:      "pre-release rule in security policy",
+			documents: []data.MarkdownDocument{security},
+			matches:   isPreReleaseRule,
+			wantFound: true,
+			wantMatch: `SECURITY.md (Remediation): "Releases are blocked while known vulnerabilities in dependencies are unresolved"`,
+		},
+		{
+			name:      "pre-release rule in untitled dependency policy is found first",
+			documents: []data.MarkdownDocument{dependencyPolicy, security},
+			matches:   isPreReleaseRule,
+			wantFound: true,
+			wantMatch: `docs/dependencies.md: "Dependency license violations must be resolved before the next release"`,
+		},
+		{
+			name:      "no threshold without a remediation window",
+			documents: []data.MarkdownDocument{dependencyPolicy},
+			matches:   isRemediationThreshold,
+			wantFound: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, found := findScaPolicyText(tt.documents, tt.matches)
+			assert.Equal(t, tt.wantFound, found)
+			if tt.wantFound {
+				assert.Equal(t, tt.wantMatch, match.String())
+			}
+		})
+	}
+}
+
+func TestIsAdvisoryFeedURL(t *testing.T) {
+	tests := []struct {
+		link     string
+		expected bool
+	}{
+		{"https://github.com/org/repo/security/advisories", true},
+		{"https://github.com/org/repo/security/advisories/", true},
+		{"https://osv.dev/list?q=org/repo", true},
+		{"https://api.osv.dev/v1/vulns/GO-2024-0001", true},
+		{"https://example.com/security/feed.xml", true},
+		{"https://example.com/advisories.atom", true},
+		{"https://example.com/.well-known/csaf/provider-metadata.json", true},
+		{"https://github.com/org/repo/security/advisories/new", false},
+		{"https://github.com/org/repo/blob/main/docs/security-advisory-process.md", false},
+		{"https://example.com/advisories", false},
+		{"https://github.com/org/repo/blob/main/SECURITY.md", false},
+		{"not a url", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isAdvisoryFeedURL(tt.link))
+		})
+	}
+}
+
+func TestDependencyScanningIsRequiredWithoutWorkflows(t *testing.T) {
+	t.Run("repository without workflows", func(t *testing.T) {
+		payload := data.NewPayloadWithWorkflows(data.Payload{}, nil)
+		result, message, _ := DependencyScanningIsRequired(payload)
+		assert.Equal(t, gemara.Failed, result)
+		assert.Equal(t, "No dependency vulnerability scanning found in workflows: No workflows found in .github/workflows directory", message)
+	})
+
+	t.Run("workflows could not be fetched", func(t *testing.T) {
+		payload := data.NewPayloadWithWorkflowsError(data.Payload{}, errors.New("403 API rate limit exceeded"))
+		result, message, _ := DependencyScanningIsRequired(payload)
+		assert.Equal(t, gemara.Unknown, result)
+		assert.Equal(t, "Failed to fetch workflows: 403 API rate limit exceeded", message)
+	})
+}
@@ -1,7 +1,9 @@
 package reusable_steps
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	"github.com/gemaraproj/go-gemara"
 
@@ -12,10 +14,50 @@ func VerifyPayload(payloadData any) (payload data.Payload, message string) {
 	payload, ok := payloadData.(data.Payload)
 	if !ok {
 		message = fmt.Sprintf("Malformed assessment: expected payload type %T, got %T (%v)", data.Payload{}, payloadData, payloadData)
+	} else if err := context.Cause(payload.Context()); err != nil {
+		message = fmt.Sprintf("Not evaluated: %s", err.Error())
 	}
 	return
 }
 
+// GetConfigList returns the values of a list config var, which may be a YAML list or a comma separated string
+func GetConfigList(payload data.Payload, key string) (values []string) {
+	if payload.Config == nil {
+		return nil
+	}
+	value, _ := payload.Config.GetVar(key)
+	switch value := value.(type) {
+	case string:
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	case []any:
+		for _, item := range value {
+			values = append(values, fmt.Sprint(item))
+		}
+	case []string:
+		values = value
+	}
+	return values
+}
+
+// RequiresGitHubAPI wraps a step that only evaluates data from the GitHub API, such as repository settings,
+// so that it is reported as not run instead of failing when the payload was read from a local clone
+func RequiresGitHubAPI(step gemara.AssessmentStep) gemara.AssessmentStep {
+	return func(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
+		payload, message := VerifyPayload(payloadData)
+		if message != "" {
+			return gemara.Unknown, message, confidence
+		}
+		if payload.IsLocalClone {
+			return gemara.NotRun, "This step requires repository settings from the GitHub API, which are unavailable when scanning a local clone", confidence
+		}
+		return step(payloadData)
+	}
+}
+
 func NotImplemented(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
 	return gemara.NotRun, "Not implemented", confidence
 }
@@ -54,11 +96,15 @@ func HasMadeReleases(payloadData any) (result gemara.Result, message string, con
 		return gemara.Unknown, message, confidence
 	}
 
-	if len(payload.Releases) == 0 {
+	releases, err := payload.Releases()
+	if err != nil {
+		return gemara.Unknown, err.Error(), confidence
+	}
+	if len(releases) == 0 {
 		return gemara.NotApplicable, "No releases found", confidence
 	}
 
-	return gemara.Passed, fmt.Sprintf("Found %v releases", len(payload.Releases)), confidence
+	return gemara.Passed, fmt.Sprintf("Found %v releases", len(releases)), confidence
 }
 
 func IsActive(payloadData any) (result gemara.Result, message string, confidence gemara.ConfidenceLevel) {
@@ -113,7 +159,14 @@ func IsCodeRepo(payloadData any) (result gemara.Result, message string, confiden
 		return gemara.Unknown, message, confidence
 	}
 
-	if !payload.IsCodeRepo {
+	if payload.RestData == nil {
+		return gemara.Unknown, "Repository data is not available in the payload", confidence
+	}
+	isCodeRepo, err := payload.IsCodeRepo()
+	if err != nil {
+		return gemara.Unknown, fmt.Sprintf("Failed to list repository languages: %s", err.Error()), confidence
+	}
+	if !isCodeRepo {
 		return gemara.NotApplicable, "Repository does not contain code", confidence
 	}
 
@@ -1,6 +1,8 @@
 package reusable_steps
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/gemaraproj/go-gemara"
@@ -83,23 +85,26 @@ func TestIsCodeRepo(t *testing.T) {
 		assertionMessage string
 	}{
 		{
-			name: "Repository contains code",
-			payloadData: data.Payload{
-				IsCodeRepo: true,
-			},
+			name:             "Repository contains code",
+			payloadData:      data.NewPayloadWithCodeRepo(data.Payload{}, true),
 			expectedResult:   gemara.Passed,
 			expectedMessage:  "Repository contains code",
 			assertionMessage: "Should pass when IsCodeRepo is true",
 		},
 		{
-			name: "Repository does not contain code",
-			payloadData: data.Payload{
-				IsCodeRepo: false,
-			},
+			name:             "Repository does not contain code",
+			payloadData:      data.NewPayloadWithCodeRepo(data.Payload{}, false),
 			expectedResult:   gemara.NotApplicable,
 			expectedMessage:  "Repository does not contain code",
 			assertionMessage: "Should be not applicable when IsCodeRepo is false",
 		},
+		{
+			name:             "Payload without repository data",
+			payloadData:      data.Payload{},
+			expectedResult:   gemara.Unknown,
+			expectedMessage:  "Repository data is not available in the payload",
+			assertionMessage: "Should return Unknown when the languages cannot be listed",
+		},
 		{
 			name:             "Malformed payload type",
 			payloadData:      "not a payload",
@@ -236,3 +241,83 @@ func TestIsActive(t *testing.T) {
 		assert.Equal(t, tt.expectedMessage, message, tt.assertionMessage)
 	}
 }
+
+func TestRequiresGitHubAPI(t *testing.T) {
+	step := RequiresGitHubAPI(IsCodeRepo)
+	tests := []struct {
+		name             string
+		payloadData      any
+		expectedResult   gemara.Result
+		expectedMessage  string
+		assertionMessage string
+	}{
+		{
+			name:             "Payload loaded from the GitHub API",
+			payloadData:      data.NewPayloadWithCodeRepo(data.Payload{}, true),
+			expectedResult:   gemara.Passed,
+			expectedMessage:  "Repository contains code",
+			assertionMessage: "Should run the wrapped step when the API was used",
+		},
+		{
+			name:             "Payload loaded from a local clone",
+			payloadData:      data.NewPayloadWithCodeRepo(data.Payload{IsLocalClone: true}, true),
+			expectedResult:   gemara.NotRun,
+			expectedMessage:  "This step requires repository settings from the GitHub API, which are unavailable when scanning a local clone",
+			assertionMessage: "Should not run the wrapped step for a local clone",
+		},
+		{
+			name:             "Malformed payload type",
+			payloadData:      "not a payload",
+			expectedResult:   gemara.Unknown,
+			expectedMessage:  "Malformed assessment: expected payload type data.Payload, got string (not a payload)",
+			assertionMessage: "Should return Unknown for wrong payload type",
+		},
+	}
+
+	for _, tt := range tests {
+		result, message, _ := step(tt.payloadData)
+		assert.Equal(t, tt.expectedResult, result, tt.assertionMessage)
+		assert.Equal(t, tt.expectedMessage, message, tt.assertionMessage)
+	}
+}
+
+func TestVerifyPayload(t *testing.T) {
+	cancelled, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("scan cancelled after the 10m0s timeout"))
+
+	tests := []struct {
+		name            string
+		payloadData     any
+		expectedMessage string
+	}{
+		{
+			name:        "Payload loaded by the Loader",
+			payloadData: data.NewPayloadWithContext(data.Payload{}, context.Background()),
+		},
+		{
+			name:        "Payload without a scan context",
+			payloadData: data.Payload{},
+		},
+		{
+			name:            "Scan cancelled",
+			payloadData:     data.NewPayloadWithContext(data.Payload{}, cancelled),
+			expectedMessage: "Not evaluated: scan cancelled after the 10m0s timeout",
+		},
+		{
+			name:            "Malformed payload type",
+			payloadData:     42,
+			expectedMessage: "Malformed assessment: expected payload type data.Payload, got int (42)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, message := VerifyPayload(tt.payloadData)
+			assert.Equal(t, tt.expectedMessage, message)
+		})
+	}
+
+	result, message, _ := IsCodeRepo(data.NewPayloadWithContext(data.NewPayloadWithCodeRepo(data.Payload{}, true), cancelled))
+	assert.Equal(t, gemara.Unknown, result, "Steps report Unknown once the scan is cancelled")
+	assert.Equal(t, "Not evaluated: scan cancelled after the 10m0s timeout", message)
+}
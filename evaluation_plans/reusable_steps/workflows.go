@@ -0,0 +1,57 @@
+package reusable_steps
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/gemaraproj/go-gemara"
+	"github.com/rhysd/actionlint"
+
+	"github.com/ossf/pvtr-github-repo-scanner/data"
+)
+
+// Workflow is a GitHub Actions workflow file parsed by actionlint
+type Workflow struct {
+	Path string
+	*actionlint.Workflow
+}
+
+// ParseWorkflows fetches and parses every YAML file in the .github/workflows directory.
+// When the workflows cannot be evaluated, a result and message are returned for the calling step to report:
+// NotApplicable when the repository has no workflows, and Unknown when they could not be fetched.
+func ParseWorkflows(payload data.Payload) (workflows []Workflow, result gemara.Result, message string) {
+	files, err := payload.Workflows()
+	if err != nil {
+		return nil, gemara.Unknown, fmt.Sprintf("Failed to fetch workflows: %s", err.Error())
+	}
+	if len(files) == 0 {
+		return nil, gemara.NotApplicable, "No workflows found in .github/workflows directory"
+	}
+
+	for _, file := range files {
+		if !strings.HasSuffix(file.GetName(), ".yml") && !strings.HasSuffix(file.GetName(), ".yaml") {
+			continue
+		}
+
+		if file.GetEncoding() != "base64" {
+			return nil, gemara.Failed, fmt.Sprintf("File %v is not base64 encoded", file.GetName())
+		}
+
+		if file.Content == nil {
+			return nil, gemara.Failed, fmt.Sprintf("File %v has no content", file.GetPath())
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(*file.Content)
+		if err != nil {
+			return nil, gemara.Failed, fmt.Sprintf("Error decoding workflow file: %v", err)
+		}
+
+		workflow, actionErrors := actionlint.Parse(decoded)
+		if actionErrors != nil {
+			return nil, gemara.Failed, fmt.Sprintf("Error parsing workflow: %v (%s)", actionErrors, file.GetPath())
+		}
+		workflows = append(workflows, Workflow{Path: file.GetPath(), Workflow: workflow})
+	}
+	return workflows, result, ""
+}
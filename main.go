@@ -28,7 +28,6 @@ var (
 	RequiredVars = []string{
 		"owner",
 		"repo",
-		"token",
 	}
 	//go:embed data/catalogs
 	files   embed.FS